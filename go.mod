@@ -2,8 +2,15 @@ module streaks-cli
 
 go 1.24
 
+require (
+	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
+	go.starlark.net v0.0.0-20250701195324-d457b4515e0e
+	golang.org/x/term v0.32.0
+	golang.org/x/text v0.26.0
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.8.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
@@ -15,7 +15,7 @@ func TestEmitActionEnvelope(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	err := emitActionOutput("task-list", "All Tasks", []byte(`{"input":true}`), result, opts)
+	err := emitActionOutput("task-list", "All Tasks", []byte(`{"input":true}`), result, nil, opts)
 	_ = w.Close()
 	os.Stdout = origStdout
 
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"streaks-cli/internal/output"
+)
+
+func runActionRepeated(ctx context.Context, actionID string, resolution shortcutResolution, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) error {
+	start := time.Now()
+	iterations := make([]actionIteration, 0, cmdOpts.count)
+	shortcutName := resolution.Shortcut
+	totalAttempts := 0
+	var last runResult
+	var runErr error
+	for i := 1; i <= cmdOpts.count; i++ {
+		if i > 1 && cmdOpts.interval > 0 {
+			select {
+			case <-ctx.Done():
+				runErr = exitError(ExitCodeActionFailed, ctx.Err())
+			case <-time.After(cmdOpts.interval):
+			}
+			if runErr != nil {
+				break
+			}
+		}
+		name, result, err := runResolvedShortcut(ctx, actionID, resolution, input, cmdOpts, opts)
+		totalAttempts += result.Attempts
+		iteration := actionIteration{
+			Iteration:  i,
+			OK:         err == nil,
+			Attempts:   result.Attempts,
+			DurationMS: result.Duration.Milliseconds(),
+		}
+		if err != nil {
+			iteration.Error = err.Error()
+			iterations = append(iterations, iteration)
+			runErr = err
+			break
+		}
+		iterations = append(iterations, iteration)
+		// Later iterations reuse the shortcut that worked instead of probing candidates again.
+		shortcutName = name
+		resolution.Shortcut, resolution.Candidates = name, nil
+		last = result
+		if !opts.noOutput && !opts.isAgent() {
+			if _, err := fmt.Fprint(os.Stdout, string(result.Output)); err != nil {
+				return err
+			}
+		}
+	}
+
+	completed := 0
+	for _, iteration := range iterations {
+		if iteration.OK {
+			completed++
+		}
+	}
+	aggregate := runResult{Output: last.Output, Attempts: totalAttempts, Duration: time.Since(start), Strategy: resolution.Strategy, Extract: last.Extract}
+	var journalErr error
+	if completed > 0 {
+		journalErr = recordJournal(actionID, shortcutName, input, aggregate, completed, cmdOpts)
+	}
+	if !opts.noOutput && opts.isAgent() {
+		envelope := buildActionEnvelope(actionID, shortcutName, input, aggregate)
+		envelope.Count = cmdOpts.count
+		envelope.Completed = completed
+		envelope.Iterations = iterations
+		if completed == 0 {
+			envelope.Result = nil
+			envelope.ResultRaw = ""
+		}
+		if runErr != nil {
+			envelope.OK = false
+			envelope.Error = runErr.Error()
+		}
+		if err := output.PrintJSON(os.Stdout, envelope, false); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if journalErr != nil {
+		return fmt.Errorf("journal write failed: %w", journalErr)
+	}
+	return nil
+}
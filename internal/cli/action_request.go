@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// actionRequest describes one action run without going through cobra flags.
+// It is the unit of work for batch input and other multi-action commands.
+type actionRequest struct {
+	ID         any             `json:"id,omitempty"`
+	Action     string          `json:"action"`
+	Task       string          `json:"task,omitempty"`
+	Status     string          `json:"status,omitempty"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Shortcut   string          `json:"shortcut,omitempty"`
+	Note       string          `json:"note,omitempty"`
+	IfPending  bool            `json:"if_pending,omitempty"`
+	UnlessDone bool            `json:"unless_done,omitempty"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+
+	Trace string `json:"-"`
+}
+
+func (r actionRequest) cmdOptions() *actionCmdOptions {
+	return &actionCmdOptions{
+		task:       strings.TrimSpace(r.Task),
+		status:     r.Status,
+		shortcut:   r.Shortcut,
+		note:       r.Note,
+		trace:      r.Trace,
+		ifPending:  r.IfPending,
+		unlessDone: r.UnlessDone,
+		dryRun:     r.DryRun,
+	}
+}
+
+func (r actionRequest) payload() ([]byte, error) {
+	raw := strings.TrimSpace(string(r.Input))
+	if raw == "" || raw == "null" {
+		return nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(r.Input, &text); err == nil {
+		return []byte(text), nil
+	}
+	return []byte(raw), nil
+}
+
+// execute resolves and runs a request inside the session and returns the
+// envelope describing the outcome. Nothing is printed; on failure the envelope
+// carries ok=false plus the error and the error is returned as well.
+func (s *actionSession) execute(ctx context.Context, req actionRequest, opts *rootOptions) (actionEnvelope, error) {
+	envelope := actionEnvelope{
+		ID:        req.ID,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Action:    actionEnvelopeInfo{ID: req.Action},
+	}
+	fail := func(err error) (actionEnvelope, error) {
+		envelope.setError(err)
+		return envelope, err
+	}
+
+	def, ok := defaultActionDef(req.Action)
+	if !ok {
+		return fail(exitError(ExitCodeUsage, fmt.Errorf("unknown action: %s", req.Action)))
+	}
+	cmdOpts := req.cmdOptions()
+	input, err := req.payload()
+	if err != nil {
+		return fail(exitError(ExitCodeUsage, err))
+	}
+	if input == nil {
+		if input, err = actionPayload(def, cmdOpts.task, cmdOpts.status); err != nil {
+			return fail(exitError(ExitCodeUsage, err))
+		}
+	} else {
+		cmdOpts.input = string(input)
+	}
+	if len(input) > 0 {
+		envelope.Input = normalizeInput(input)
+	}
+	task := actionTask(cmdOpts, input)
+	if cmdOpts.hasGuard() && !guardActions[def.ID] {
+		return fail(exitError(ExitCodeUsage, fmt.Errorf("guards are only supported for task actions (%s)", def.ID)))
+	}
+	if cmdOpts.hasGuard() && task == "" {
+		return fail(exitError(ExitCodeUsage, errMissingGuardTask))
+	}
+
+	resolution, err := s.resolve(ctx, def, cmdOpts, opts)
+	if err != nil {
+		return fail(err)
+	}
+	envelope.Shortcut = actionShortcutInfo{Name: resolution.first()}
+	envelope.Strategy = resolution.Strategy
+	if cmdOpts.dryRun {
+		plan, err := buildDryRunPlan(def.ID, resolution, input, cmdOpts, opts)
+		if err != nil {
+			return fail(err)
+		}
+		envelope.OK = true
+		envelope.DryRun = true
+		envelope.Plan = &plan
+		return envelope, nil
+	}
+	if cmdOpts.hasGuard() {
+		guard, err := evaluateGuard(ctx, s, task, cmdOpts, opts)
+		if err != nil {
+			return fail(err)
+		}
+		if !guard.Pass {
+			skipped := skippedActionEnvelope(def.ID, resolution.first(), input, guard)
+			skipped.ID = req.ID
+			skipped.Strategy = resolution.Strategy
+			return skipped, nil
+		}
+	}
+
+	warnings := timerStartWarnings(def.ID, task)
+	envelope.Warnings = warnings
+	name, result, err := runResolvedShortcut(ctx, def.ID, resolution, input, cmdOpts, withOutput(opts))
+	if err != nil {
+		if name != "" {
+			envelope.Shortcut = actionShortcutInfo{Name: name}
+		}
+		envelope.Attempts = result.Attempts
+		envelope.DurationMS = result.Duration.Milliseconds()
+		return fail(err)
+	}
+	done := buildActionEnvelope(def.ID, name, input, result)
+	done.ID = req.ID
+	done.Warnings = append(done.Warnings, warnings...)
+	if journalErr := recordJournal(def.ID, name, input, result, 1, cmdOpts); journalErr != nil {
+		done.Warnings = append(done.Warnings, "journal write failed: "+journalErr.Error())
+	}
+	done.Warnings = append(done.Warnings, recordTimerState(def.ID, task, name)...)
+	return done, nil
+}
+
+func errorEnvelope(id any, actionID string, err error) actionEnvelope {
+	envelope := actionEnvelope{
+		ID:        id,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Action:    actionEnvelopeInfo{ID: actionID},
+	}
+	envelope.setError(err)
+	return envelope
+}
+
+func (e *actionEnvelope) setError(err error) {
+	e.OK = false
+	e.Error = err.Error()
+	e.Code = 1
+	e.ErrorCode = ""
+	var ee ExitError
+	if errors.As(err, &ee) {
+		e.Code = ee.Code
+		e.ErrorCode = errorCodeLabel(ee.Code)
+	}
+}
@@ -9,43 +9,70 @@ import (
 	"time"
 )
 
-import "streaks-cli/internal/output"
+import (
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/shortcuts"
+)
 
-func runNamedShortcut(ctx context.Context, actionID, name string, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) error {
-	result, err := runShortcutOnce(ctx, name, input, opts)
+func runResolvedShortcut(ctx context.Context, actionID string, resolution shortcutResolution, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) (string, runResult, error) {
+	if resolution.Shortcut != "" {
+		payload, err := resolution.adaptInput(resolution.Shortcut, input)
+		if err != nil {
+			return resolution.Shortcut, runResult{}, exitError(ExitCodeUsage, err)
+		}
+		run := resolution.runOptions(resolution.Shortcut, cmdOpts)
+		result, err := runNamedShortcut(ctx, resolution.Shortcut, payload, run, cmdOpts, opts)
+		result.Strategy = resolution.Strategy
+		result.Extract = resolution.output(resolution.Shortcut).Extract
+		return resolution.Shortcut, result, err
+	}
+	name, result, err := runCandidateShortcuts(ctx, resolution, actionID, input, cmdOpts, opts)
+	result.Strategy = resolution.Strategy
+	return name, result, err
+}
+
+func runNamedShortcut(ctx context.Context, name string, input []byte, run shortcuts.RunOptions, cmdOpts *actionCmdOptions, opts *rootOptions) (runResult, error) {
+	result, err := runShortcutOnce(ctx, name, input, run, opts)
 	if err != nil {
-		_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, Error: err.Error()})
+		_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, InputFiles: run.InputFiles, Error: err.Error()})
 		if isShortcutNotFound(err) {
-			return exitError(ExitCodeShortcutMissing, err)
+			return result, exitError(ExitCodeShortcutMissing, err)
 		}
-		return exitError(ExitCodeActionFailed, err)
+		return result, exitError(ExitCodeActionFailed, err)
 	}
-	_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, Output: result.Output})
-	return emitActionOutput(actionID, name, input, result, opts)
+	_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, InputFiles: run.InputFiles, Output: result.Output})
+	return result, nil
 }
 
-func runCandidateShortcuts(ctx context.Context, candidates []string, actionID string, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) error {
+func runCandidateShortcuts(ctx context.Context, resolution shortcutResolution, actionID string, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) (string, runResult, error) {
+	candidates := resolution.Candidates
 	if len(candidates) == 0 {
-		return exitError(ExitCodeShortcutMissing, fmt.Errorf("no matching Streaks shortcut found for action %s", actionID))
+		return "", runResult{}, exitError(ExitCodeShortcutMissing, fmt.Errorf("no matching Streaks shortcut found for action %s", actionID))
 	}
 	for _, name := range candidates {
-		result, err := runShortcutOnce(ctx, name, input, opts)
+		payload, err := resolution.adaptInput(name, input)
+		if err != nil {
+			return name, runResult{}, exitError(ExitCodeUsage, err)
+		}
+		run := resolution.runOptions(name, cmdOpts)
+		result, err := runShortcutOnce(ctx, name, payload, run, opts)
+		result.Extract = resolution.output(name).Extract
 		if err != nil {
 			if isShortcutNotFound(err) {
 				continue
 			}
-			_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, Error: err.Error()})
-			return exitError(ExitCodeActionFailed, err)
+			_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: payload, InputFiles: run.InputFiles, Error: err.Error()})
+			return name, result, exitError(ExitCodeActionFailed, err)
 		}
-		_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: input, Output: result.Output})
-		return emitActionOutput(actionID, name, input, result, opts)
+		_ = appendTrace(cmdOpts.trace, traceEntry{Shortcut: name, Input: payload, InputFiles: run.InputFiles, Output: result.Output})
+		return name, result, nil
 	}
-	return exitError(ExitCodeShortcutMissing, fmt.Errorf("no matching Streaks shortcut found for action %s; expected one of: %s", actionID, strings.Join(candidates, ", ")))
+	return "", runResult{}, exitError(ExitCodeShortcutMissing, fmt.Errorf("no matching Streaks shortcut found for action %s; expected one of: %s", actionID, strings.Join(candidates, ", ")))
 }
 
-func runShortcutOnce(ctx context.Context, name string, input []byte, opts *rootOptions) (runResult, error) {
+func runShortcutOnce(ctx context.Context, name string, input []byte, run shortcuts.RunOptions, opts *rootOptions) (runResult, error) {
 	if opts != nil && opts.noOutput {
-		result, err := runShortcutWithRetry(ctx, name, input, opts)
+		result, err := runShortcutWithRetry(ctx, name, input, run, opts)
 		result.Output = nil
 		return result, err
 	}
@@ -55,20 +82,42 @@ func runShortcutOnce(ctx context.Context, name string, input []byte, opts *rootO
 		ctxRun, cancel = context.WithTimeout(ctx, opts.timeout)
 		defer cancel()
 	}
-	return runShortcutWithRetry(ctxRun, name, input, opts)
+	return runShortcutWithRetry(ctxRun, name, input, run, opts)
 }
 
-func emitActionOutput(actionID, shortcutName string, input []byte, result runResult, opts *rootOptions) error {
+func finishAction(actionID, shortcutName string, input []byte, result runResult, warnings []string, cmdOpts *actionCmdOptions, opts *rootOptions) error {
+	// The shortcut already ran, so bookkeeping failures are warnings: failing
+	// here would make callers retry an action that succeeded.
+	if journalErr := recordJournal(actionID, shortcutName, input, result, 1, cmdOpts); journalErr != nil {
+		warnings = append(warnings, "journal write failed: "+journalErr.Error())
+	}
+	warnings = append(warnings, recordTimerState(actionID, actionTask(cmdOpts, input), shortcutName)...)
+	return emitActionOutput(actionID, shortcutName, input, result, warnings, opts)
+}
+
+func emitActionOutput(actionID, shortcutName string, input []byte, result runResult, warnings []string, opts *rootOptions) error {
 	if opts != nil && opts.noOutput {
 		return nil
 	}
 	if opts != nil && opts.isAgent() {
-		return output.PrintJSON(os.Stdout, buildActionEnvelope(actionID, shortcutName, input, result), false)
+		envelope := buildActionEnvelope(actionID, shortcutName, input, result)
+		envelope.Warnings = append(envelope.Warnings, warnings...)
+		return output.PrintJSON(os.Stdout, envelope, false)
 	}
+	printWarnings(warnings, opts)
 	_, err := fmt.Fprint(os.Stdout, string(result.Output))
 	return err
 }
 
+func printWarnings(warnings []string, opts *rootOptions) {
+	if opts != nil && opts.noOutput {
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning: "+warning)
+	}
+}
+
 func isShortcutNotFound(err error) bool {
 	if err == nil {
 		return false
@@ -96,14 +145,39 @@ func normalizeShortcutOutput(out []byte, shortcutName string) any {
 }
 
 type actionEnvelope struct {
-	OK         bool               `json:"ok"`
-	Timestamp  string             `json:"timestamp"`
-	Action     actionEnvelopeInfo `json:"action"`
-	Shortcut   actionShortcutInfo `json:"shortcut"`
-	Attempts   int                `json:"attempts"`
-	DurationMS int64              `json:"duration_ms"`
-	Input      any                `json:"input,omitempty"`
-	Result     any                `json:"result,omitempty"`
+	ID             any                `json:"id,omitempty"`
+	OK             bool               `json:"ok"`
+	DryRun         bool               `json:"dry_run,omitempty"`
+	Skipped        bool               `json:"skipped,omitempty"`
+	Reason         string             `json:"reason,omitempty"`
+	Timestamp      string             `json:"timestamp"`
+	Action         actionEnvelopeInfo `json:"action"`
+	Shortcut       actionShortcutInfo `json:"shortcut"`
+	Attempts       int                `json:"attempts"`
+	DurationMS     int64              `json:"duration_ms"`
+	Input          any                `json:"input,omitempty"`
+	Result         any                `json:"result,omitempty"`
+	ResultRaw      string             `json:"result_raw,omitempty"`
+	Count          int                `json:"count,omitempty"`
+	Completed      int                `json:"completed,omitempty"`
+	Iterations     []actionIteration  `json:"iterations,omitempty"`
+	Strategy       string             `json:"strategy,omitempty"`
+	Guard          *guardOutcome      `json:"guard,omitempty"`
+	Plan           *dryRunPlan        `json:"plan,omitempty"`
+	Interpretation *doInterpretation  `json:"interpretation,omitempty"`
+	Suggestions    []doInterpretation `json:"suggestions,omitempty"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	Error          string             `json:"error,omitempty"`
+	Code           int                `json:"code,omitempty"`
+	ErrorCode      string             `json:"error_code,omitempty"`
+}
+
+type actionIteration struct {
+	Iteration  int    `json:"iteration"`
+	OK         bool   `json:"ok"`
+	Attempts   int    `json:"attempts"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
 }
 
 type actionEnvelopeInfo struct {
@@ -120,10 +194,19 @@ func buildActionEnvelope(actionID, shortcutName string, input []byte, result run
 		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
 		Action:     actionEnvelopeInfo{ID: actionID},
 		Shortcut:   actionShortcutInfo{Name: shortcutName},
+		Strategy:   result.Strategy,
 		Attempts:   result.Attempts,
 		DurationMS: result.Duration.Milliseconds(),
 		Result:     normalizeShortcutOutput(result.Output, shortcutName),
 	}
+	if result.Extract != nil && len(result.Output) > 0 {
+		if value, err := result.Extract.Apply(result.Output); err == nil {
+			envelope.Result = value
+			envelope.ResultRaw = string(result.Output)
+		} else {
+			envelope.Warnings = append(envelope.Warnings, fmt.Sprintf("extract %s: %v", result.Extract, err))
+		}
+	}
 	if len(input) > 0 {
 		envelope.Input = normalizeInput(input)
 	}
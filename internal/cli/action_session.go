@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/shortcuts"
+)
+
+// actionSession caches discovery, the shortcut library and config so that
+// commands running several actions resolve them only once.
+type actionSession struct {
+	// folder is the --folder flag; empty falls back to the config.
+	folder string
+
+	discovered bool
+	disc       discovery.Discovery
+	discErr    error
+
+	// lists caches the shortcut list per effective folder, so a shell line
+	// with its own --folder does not reuse another folder's list.
+	lists map[string]shortcutList
+
+	configured bool
+	cfg        config.Config
+	cfgErr     error
+}
+
+type shortcutList struct {
+	list []shortcuts.Shortcut
+	err  error
+}
+
+func newActionSession() *actionSession {
+	return &actionSession{}
+}
+
+func (s *actionSession) discovery(ctx context.Context) (discovery.Discovery, error) {
+	if !s.discovered {
+		s.disc, s.discErr = discover(ctx)
+		s.discovered = true
+	}
+	return s.disc, s.discErr
+}
+
+func (s *actionSession) shortcuts(ctx context.Context) ([]shortcuts.Shortcut, error) {
+	folder := s.shortcutsFolder()
+	cached, ok := s.lists[folder]
+	if !ok {
+		cached.list, cached.err = listShortcuts(ctx, folder)
+		if s.lists == nil {
+			s.lists = make(map[string]shortcutList)
+		}
+		s.lists[folder] = cached
+	}
+	return cached.list, cached.err
+}
+
+// shortcutsFolder is the folder shortcut lookups are limited to. A config
+// error is ignored here; resolve reports it.
+func (s *actionSession) shortcutsFolder() string {
+	cfg, _ := s.config()
+	return shortcutsFolder(s.folder, cfg)
+}
+
+// shortcutsFolder is the --folder flag, else the config's shortcuts_folder.
+func shortcutsFolder(flag string, cfg config.Config) string {
+	if folder := strings.TrimSpace(flag); folder != "" {
+		return folder
+	}
+	return strings.TrimSpace(cfg.ShortcutsFolder)
+}
+
+func (s *actionSession) config() (config.Config, error) {
+	if !s.configured {
+		s.cfg, _, s.cfgErr = config.Load()
+		s.configured = true
+	}
+	return s.cfg, s.cfgErr
+}
+
+// forgetConfig drops the cached config after a command rewrote it.
+func (s *actionSession) forgetConfig() {
+	s.configured = false
+}
+
+// Resolution sources: the --shortcut flag, a config mapping (per task or per
+// action) or discovery.
+const (
+	sourceFlag        = "flag"
+	sourceMapping     = "mapping"
+	sourceTaskMapping = "task_mapping"
+	sourceDiscovery   = "discovery"
+
+	// originFallback marks a mapping fallback among resolution candidates.
+	originFallback = "fallback"
+)
+
+type shortcutResolution struct {
+	Shortcut   string
+	Candidates []string
+	// Candidate is the candidate name Shortcut was matched from.
+	Candidate string
+	// Refs holds the mapping and its fallbacks by candidate name.
+	Refs map[string]config.ShortcutRef
+	// Output is the action's default output type and extractor.
+	Output outputSpec
+
+	// Details for dry runs.
+	Source     string
+	Strategy   string
+	Mapping    *config.ShortcutRef
+	Origins    []discovery.ShortcutCandidate
+	Match      string
+	Score      float64
+	NearMisses []shortcutMatch
+	ListErr    error
+}
+
+func (r shortcutResolution) first() string {
+	if r.Shortcut != "" {
+		return r.Shortcut
+	}
+	if len(r.Candidates) > 0 {
+		return r.Candidates[0]
+	}
+	return ""
+}
+
+// ref returns the mapping entry for the candidate that runs as name.
+func (r shortcutResolution) ref(name string) (config.ShortcutRef, bool) {
+	if name == r.Shortcut && r.Candidate != "" {
+		name = r.Candidate
+	}
+	ref, ok := r.Refs[name]
+	return ref, ok
+}
+
+func (r shortcutResolution) adapter(name string) *config.InputAdapter {
+	ref, _ := r.ref(name)
+	return ref.Input
+}
+
+// output is the mapping's output settings for name when it declares any,
+// else the action default.
+func (r shortcutResolution) output(name string) outputSpec {
+	if ref, ok := r.ref(name); ok && (ref.Output != "" || ref.Extract != nil) {
+		return outputSpec{Type: ref.Output, Extract: ref.Extract}
+	}
+	return r.Output
+}
+
+// adaptInput reshapes the action payload for the shortcut run as name.
+func (r shortcutResolution) adaptInput(name string, input []byte) ([]byte, error) {
+	adapter := r.adapter(name)
+	if adapter == nil {
+		return input, nil
+	}
+	return adapter.Apply(input)
+}
+
+// runOptions is how the shortcut run as name receives its input and output:
+// text for --input-text or a text adapter, plus any --input-file paths and the
+// --save path.
+func (r shortcutResolution) runOptions(name string, cmdOpts *actionCmdOptions) shortcuts.RunOptions {
+	run := shortcuts.RunOptions{OutputType: r.output(name).Type}
+	if cmdOpts != nil {
+		run.InputFiles = cmdOpts.inputFiles
+		run.SavePath = cmdOpts.save
+		if cmdOpts.inputText != "" {
+			run.InputType = shortcuts.InputText
+		}
+	}
+	if adapter := r.adapter(name); adapter != nil && adapter.Kind == config.AdapterText {
+		run.InputType = shortcuts.InputText
+	}
+	return run
+}
+
+// resolve picks the shortcut for an action: --shortcut, then the config
+// mapping and its fallbacks, then discovered candidates ordered by the
+// strategy. Candidates are matched against the shortcut library when it can be
+// listed; otherwise they are tried in order at run time.
+func (s *actionSession) resolve(ctx context.Context, def discovery.ActionDef, cmdOpts *actionCmdOptions, opts *rootOptions) (shortcutResolution, error) {
+	output := actionOutput(def)
+	// --shortcut bypasses resolution, so neither the strategy nor the config
+	// is consulted.
+	if cmdOpts.shortcut != "" {
+		return shortcutResolution{Shortcut: cmdOpts.shortcut, Source: sourceFlag, Output: output}, nil
+	}
+	cfg, err := s.config()
+	if err != nil {
+		return shortcutResolution{}, err
+	}
+	strategy, err := effectiveStrategy(cfg, opts)
+	if err != nil {
+		return shortcutResolution{}, exitError(ExitCodeUsage, err)
+	}
+
+	taskForShortcut := cmdOpts.task
+	if taskForShortcut == "" {
+		if task := taskFromInput(cmdOpts.input); task != "" {
+			taskForShortcut = task
+		}
+	}
+	if source, ref, ok := configMapping(cfg, def.ID, taskForShortcut); ok {
+		if origins := mappingCandidates(ref, source); len(origins) > 0 {
+			resolution := shortcutResolution{Source: source, Mapping: &ref, Strategy: strategy, Refs: mappingRefs(ref), Output: output}
+			if len(origins) == 1 {
+				resolution.Shortcut = origins[0].Name
+				return resolution, nil
+			}
+			return s.matchCandidates(ctx, resolution, origins, opts.allowFuzzy()), nil
+		}
+	}
+	if strategy == config.StrategyMappingOnly {
+		return shortcutResolution{}, exitError(ExitCodeShortcutMissing, fmt.Errorf("no mapping for action %s (strategy %s); run st link %s", def.ID, strategy, def.ID))
+	}
+
+	disc, err := s.discovery(ctx)
+	if err != nil {
+		return shortcutResolution{}, exitError(ExitCodeAppMissing, err)
+	}
+	origins := orderCandidates(actionCandidateDetails(def, disc, taskForShortcut), strategy)
+	if len(origins) == 0 {
+		return shortcutResolution{}, exitError(ExitCodeShortcutMissing, fmt.Errorf("no shortcut candidates found for action %s", def.ID))
+	}
+	return s.matchCandidates(ctx, shortcutResolution{Source: sourceDiscovery, Strategy: strategy, Output: output}, origins, opts.allowFuzzy()), nil
+}
+
+func (s *actionSession) matchCandidates(ctx context.Context, resolution shortcutResolution, origins []discovery.ShortcutCandidate, fuzzy bool) shortcutResolution {
+	resolution.Origins = origins
+	candidates := make([]string, 0, len(origins))
+	for _, cand := range origins {
+		candidates = append(candidates, cand.Name)
+	}
+	available, err := s.shortcuts(ctx)
+	if err == nil {
+		if match := matchShortcut(available, candidates, fuzzy); match.Name != "" {
+			resolution.Shortcut = match.Name
+			resolution.Candidate = match.Candidate
+			resolution.Match = match.Kind
+			resolution.Score = match.Score
+			return resolution
+		}
+		resolution.NearMisses = nearShortcutMisses(available, candidates, fuzzy)
+	}
+	resolution.Candidates = candidates
+	resolution.ListErr = err
+	return resolution
+}
+
+// effectiveStrategy is --strategy, else the config's prefer setting, else auto.
+func effectiveStrategy(cfg config.Config, opts *rootOptions) (string, error) {
+	if opts != nil && opts.strategy != "" {
+		return config.ParseStrategy(opts.strategy)
+	}
+	strategy, err := config.ParseStrategy(cfg.Prefer)
+	if err != nil {
+		return "", fmt.Errorf("config prefer: %w", err)
+	}
+	return strategy, nil
+}
+
+// configMapping returns the task mapping for task if there is one, else the
+// action mapping, with the matching resolution source.
+func configMapping(cfg config.Config, actionID, task string) (string, config.ShortcutRef, bool) {
+	if _, ref, ok := cfg.TaskMapping(actionID, task); ok {
+		return sourceTaskMapping, ref, true
+	}
+	ref, ok := cfg.Mappings[actionID]
+	return sourceMapping, ref, ok
+}
+
+func isMappingSource(source string) bool {
+	return source == sourceMapping || source == sourceTaskMapping
+}
+
+// mappingCandidates lists a mapping and its fallbacks in order; origin is
+// sourceMapping or sourceTaskMapping. A ref with both a name and an ID is
+// listed twice, name first, so it still resolves after a rename.
+func mappingCandidates(ref config.ShortcutRef, origin string) []discovery.ShortcutCandidate {
+	var out []discovery.ShortcutCandidate
+	for i, r := range append([]config.ShortcutRef{ref}, ref.Fallbacks...) {
+		if i > 0 {
+			origin = originFallback
+		}
+		for _, name := range refNames(r) {
+			out = append(out, discovery.ShortcutCandidate{Name: name, Origin: origin})
+		}
+	}
+	return out
+}
+
+func refNames(ref config.ShortcutRef) []string {
+	var names []string
+	if ref.Name != "" {
+		names = append(names, ref.Name)
+	}
+	if ref.ID != "" && ref.ID != ref.Name {
+		names = append(names, ref.ID)
+	}
+	return names
+}
+
+// mappingRefs indexes a mapping and its fallbacks by candidate name.
+func mappingRefs(ref config.ShortcutRef) map[string]config.ShortcutRef {
+	refs := make(map[string]config.ShortcutRef)
+	for _, r := range append([]config.ShortcutRef{ref}, ref.Fallbacks...) {
+		for _, name := range refNames(r) {
+			if _, ok := refs[name]; !ok {
+				refs[name] = r
+			}
+		}
+	}
+	return refs
+}
+
+// orderCandidates reorders discovered candidates for a strategy, keeping the
+// relative order within each origin.
+func orderCandidates(candidates []discovery.ShortcutCandidate, strategy string) []discovery.ShortcutCandidate {
+	var rank func(origin string) int
+	switch strategy {
+	case config.StrategyWrappersFirst:
+		rank = func(origin string) int {
+			if origin == originWrapper {
+				return 0
+			}
+			return 1
+		}
+	case config.StrategyIntentsFirst:
+		rank = func(origin string) int {
+			switch origin {
+			case discovery.OriginIntentKey, discovery.OriginPhrase:
+				return 0
+			case discovery.OriginTitle:
+				return 1
+			default:
+				return 2
+			}
+		}
+	default:
+		return candidates
+	}
+	ordered := append([]discovery.ShortcutCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i].Origin) < rank(ordered[j].Origin)
+	})
+	return ordered
+}
+
+type actionInvocation struct {
+	Action   string
+	Shortcut string
+	Input    []byte
+	Result   runResult
+}
+
+// invoke resolves and runs an action without printing anything. The payload is
+// built from the task and status only, so it never consumes stdin.
+func (s *actionSession) invoke(ctx context.Context, actionID, task, status string, opts *rootOptions) (actionInvocation, error) {
+	inv := actionInvocation{Action: actionID}
+	def, ok := defaultActionDef(actionID)
+	if !ok {
+		return inv, exitError(ExitCodeUsage, fmt.Errorf("unknown action: %s", actionID))
+	}
+	input, err := actionPayload(def, task, status)
+	if err != nil {
+		return inv, exitError(ExitCodeUsage, err)
+	}
+	inv.Input = input
+	cmdOpts := &actionCmdOptions{task: task, status: status}
+	resolution, err := s.resolve(ctx, def, cmdOpts, opts)
+	if err != nil {
+		return inv, err
+	}
+	name, result, err := runResolvedShortcut(ctx, actionID, resolution, input, cmdOpts, withOutput(opts))
+	inv.Shortcut = name
+	inv.Result = result
+	return inv, err
+}
+
+// withOutput returns options that keep shortcut output even under --no-output,
+// for callers that need to inspect the result rather than print it.
+func withOutput(opts *rootOptions) *rootOptions {
+	if opts == nil || !opts.noOutput {
+		return opts
+	}
+	copied := *opts
+	copied.noOutput = false
+	return &copied
+}
+
+func defaultActionDef(id string) (discovery.ActionDef, bool) {
+	for _, def := range discovery.DefaultActionDefinitions() {
+		if def.ID == id && def.Transport == discovery.TransportShortcuts {
+			return def, true
+		}
+	}
+	return discovery.ActionDef{}, false
+}
+
+func actionPayload(def discovery.ActionDef, task, status string) ([]byte, error) {
+	payload := map[string]any{}
+	if def.RequiresTask {
+		if strings.TrimSpace(task) == "" {
+			return nil, fmt.Errorf("action %s requires a task", def.ID)
+		}
+		payload["task"] = strings.TrimSpace(task)
+	}
+	if status != "" {
+		payload["status"] = status
+	}
+	if len(payload) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(payload)
+}
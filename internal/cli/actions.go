@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,12 +9,11 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"streaks-cli/internal/config"
 	"streaks-cli/internal/discovery"
-	"streaks-cli/internal/output"
 	"streaks-cli/internal/shortcuts"
 )
 
@@ -25,10 +25,31 @@ type actionCmdOptions struct {
 	stdin    bool
 	trace    string
 	shortcut string
+	note     string
+	count    int
+	interval time.Duration
+	check    bool
+	expect   string
+
+	ifPending  bool
+	unlessDone bool
+
+	// inputText is sent as a .txt file instead of JSON.
+	inputText string
+	// inputFiles are passed to the shortcut after the input.
+	inputFiles []string
+	// save is where output files are written instead of inline.
+	save string
+}
+
+// saveActions export files and accept --save.
+var saveActions = map[string]bool{
+	"export-all":  true,
+	"export-task": true,
 }
 
 var runShortcut = shortcuts.RunWithOptions
-var listShortcuts = shortcuts.List
+var listShortcuts = shortcuts.ListFolder
 var discover = discovery.Discover
 
 func addActionCommands(root *cobra.Command, defs []discovery.ActionDef, opts *rootOptions) {
@@ -41,12 +62,17 @@ func addActionCommands(root *cobra.Command, defs []discovery.ActionDef, opts *ro
 		cmd := &cobra.Command{
 			Use:   def.ID,
 			Short: def.Title,
-			RunE: func(_ *cobra.Command, _ []string) error {
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				if cmd.Flags().Changed("count") && cmdOpts.count < 1 {
+					return exitError(ExitCodeUsage, errors.New("--count must be at least 1"))
+				}
 				return runActionCommand(context.Background(), def, cmdOpts, opts)
 			},
 		}
-		cmd.Flags().StringVar(&cmdOpts.input, "input", "", "Raw input to pass to the shortcut (overrides --task/--status)")
-		cmd.Flags().BoolVar(&cmdOpts.stdin, "stdin", false, "Force reading input from stdin")
+		cmd.Flags().StringVar(&cmdOpts.input, "input", "", "Raw JSON input to pass to the shortcut, @file to read a file, - for stdin (overrides --task/--status)")
+		cmd.Flags().StringVar(&cmdOpts.inputText, "input-text", "", "Plain text input to pass to the shortcut (overrides --task/--status)")
+		cmd.Flags().StringArrayVar(&cmdOpts.inputFiles, "input-file", nil, "File to pass to the shortcut after the input (repeatable)")
+		cmd.Flags().BoolVar(&cmdOpts.stdin, "stdin", false, "Read input from stdin (same as --input -)")
 		cmd.Flags().BoolVar(&cmdOpts.dryRun, "dry-run", false, "Print shortcut and payload without running")
 		cmd.Flags().StringVar(&cmdOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
 		cmd.Flags().StringVar(&cmdOpts.shortcut, "shortcut", "", "Run a specific shortcut by name/identifier (overrides auto-detection)")
@@ -56,81 +82,82 @@ func addActionCommands(root *cobra.Command, defs []discovery.ActionDef, opts *ro
 		if len(def.ParamOptions) > 0 {
 			cmd.Flags().StringVar(&cmdOpts.status, "status", "", "Status value for the action")
 		}
+		if journalActions[def.ID] {
+			cmd.Flags().StringVar(&cmdOpts.note, "note", "", "Record a note for this action in the local journal")
+			cmd.Flags().IntVar(&cmdOpts.count, "count", 1, "Run the action N times (for multi-count tasks)")
+			cmd.Flags().DurationVar(&cmdOpts.interval, "interval", 0, "Delay between repeated runs when --count > 1")
+		}
+		if guardActions[def.ID] {
+			cmd.Flags().BoolVar(&cmdOpts.ifPending, "if-pending", false, "Only run when task-status reports the task as pending")
+			cmd.Flags().BoolVar(&cmdOpts.unlessDone, "unless-done", false, "Skip when task-status reports the task as done")
+		}
+		if saveActions[def.ID] {
+			cmd.Flags().StringVar(&cmdOpts.save, "save", "", "Save the exported file to this path (a directory when there are several)")
+		}
+		if def.ID == "task-status" {
+			cmd.Flags().BoolVar(&cmdOpts.check, "check", false, "Exit 0 when the task is done, 1 otherwise, 14 when unknown (silent unless --verbose)")
+			cmd.Flags().StringVar(&cmdOpts.expect, "expect", "", "Status to check for: done, pending, or missed (implies --check)")
+		}
 		root.AddCommand(cmd)
 	}
 }
 
 func runActionCommand(ctx context.Context, def discovery.ActionDef, cmdOpts *actionCmdOptions, opts *rootOptions) error {
-	input, err := buildActionInput(def, cmdOpts)
+	input, err := buildActionInput(def, cmdOpts, opts)
 	if err != nil {
 		return exitError(ExitCodeUsage, err)
 	}
-	if cmdOpts.shortcut != "" {
-		return runNamedShortcut(ctx, def.ID, cmdOpts.shortcut, input, cmdOpts, opts)
-	}
-
-	if mapped, ok, err := resolveActionMapping(def.ID); err != nil {
-		return err
-	} else if ok {
-		if cmdOpts.dryRun {
-			return printDryRun(opts, mapped, input)
-		}
-		return runNamedShortcut(ctx, def.ID, mapped, input, cmdOpts, opts)
+	expect, err := statusExpectation(cmdOpts)
+	if err != nil {
+		return exitError(ExitCodeUsage, err)
 	}
-
-	taskForShortcut := cmdOpts.task
-	if taskForShortcut == "" {
-		if task := taskFromInput(cmdOpts.input); task != "" {
-			taskForShortcut = task
-		}
+	task := actionTask(cmdOpts, input)
+	if cmdOpts.hasGuard() && task == "" {
+		return exitError(ExitCodeUsage, errMissingGuardTask)
 	}
-	candidates, err := actionCandidates(ctx, def, taskForShortcut)
+	session := opts.actionSession()
+	resolution, err := session.resolve(ctx, def, cmdOpts, opts)
 	if err != nil {
 		return err
 	}
-	if len(candidates) == 0 {
-		return exitError(ExitCodeShortcutMissing, fmt.Errorf("no shortcut candidates found for action %s", def.ID))
-	}
-
-	if available, err := listShortcuts(ctx); err == nil {
-		if match := matchShortcutName(available, candidates); match != "" {
-			if cmdOpts.dryRun {
-				return printDryRun(opts, match, input)
-			}
-			return runNamedShortcut(ctx, def.ID, match, input, cmdOpts, opts)
+	if cmdOpts.dryRun {
+		plan, err := buildDryRunPlan(def.ID, resolution, input, cmdOpts, opts)
+		if err != nil {
+			return err
 		}
+		return printDryRun(opts, cmdOpts, plan)
 	}
-
-	if cmdOpts.dryRun {
-		return printDryRun(opts, candidates[0], input)
+	if cmdOpts.hasGuard() {
+		guard, err := evaluateGuard(ctx, session, task, cmdOpts, opts)
+		if err != nil {
+			return err
+		}
+		if !guard.Pass {
+			return emitSkipped(def.ID, resolution.first(), input, guard, opts)
+		}
 	}
-	return runCandidateShortcuts(ctx, candidates, def.ID, input, cmdOpts, opts)
-}
-
-func actionCandidates(ctx context.Context, def discovery.ActionDef, task string) ([]string, error) {
-	disc, err := discover(ctx)
-	if err != nil {
-		return nil, exitError(ExitCodeAppMissing, err)
+	if cmdOpts.count > 1 {
+		return runActionRepeated(ctx, def.ID, resolution, input, cmdOpts, opts)
 	}
-	return actionCandidatesFromDiscovery(def, disc, task), nil
-}
-
-func resolveActionMapping(actionID string) (string, bool, error) {
-	cfg, _, err := config.Load()
-	if err != nil {
-		return "", false, err
-	}
-	ref, ok := cfg.Mappings[actionID]
-	if !ok {
-		return "", false, nil
+	if expect != "" {
+		name, result, err := runResolvedShortcut(ctx, def.ID, resolution, input, cmdOpts, withOutput(opts))
+		if err != nil {
+			return err
+		}
+		return checkTaskStatus(task, name, result, expect, opts)
 	}
-	if ref.Name != "" {
-		return ref.Name, true, nil
+	// Human output shows the warnings before the shortcut runs; agent mode
+	// carries them in the envelope.
+	warnings := timerStartWarnings(def.ID, task)
+	if !opts.isAgent() {
+		printWarnings(warnings, opts)
+		warnings = nil
 	}
-	if ref.ID != "" {
-		return ref.ID, true, nil
+	name, result, err := runResolvedShortcut(ctx, def.ID, resolution, input, cmdOpts, opts)
+	if err != nil {
+		return err
 	}
-	return "", false, nil
+	return finishAction(def.ID, name, input, result, warnings, cmdOpts, opts)
 }
 
 func actionCandidatesFromDiscovery(def discovery.ActionDef, disc discovery.Discovery, task string) []string {
@@ -138,41 +165,72 @@ func actionCandidatesFromDiscovery(def discovery.ActionDef, disc discovery.Disco
 	return addWrapperCandidates(def.ID, candidates)
 }
 
-func buildActionInput(def discovery.ActionDef, cmdOpts *actionCmdOptions) ([]byte, error) {
-	if cmdOpts.input != "" {
-		return []byte(cmdOpts.input), nil
-	}
+func actionCandidateDetails(def discovery.ActionDef, disc discovery.Discovery, task string) []discovery.ShortcutCandidate {
+	candidates := discovery.ActionShortcutCandidateDetails(def, disc.App, disc.AppIntentKeys, disc.AppShortcutPhrases, task)
+	return addWrapperCandidateDetails(def.ID, candidates)
+}
 
-	if cmdOpts.stdin || !isTTY(os.Stdin) {
-		data, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			return nil, err
+// buildActionInput returns --input (@file reads a file, - reads stdin),
+// --input-text or stdin with --stdin, else the payload built from --task and
+// --status. Stdin is only read when asked for, so a non-interactive caller
+// such as cron never blocks on or consumes it.
+func buildActionInput(def discovery.ActionDef, cmdOpts *actionCmdOptions, opts *rootOptions) ([]byte, error) {
+	for _, path := range cmdOpts.inputFiles {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("--input-file: %w", err)
 		}
-		if len(data) > 0 {
-			return data, nil
+	}
+	given := 0
+	for _, set := range []bool{cmdOpts.input != "", cmdOpts.inputText != "", cmdOpts.stdin} {
+		if set {
+			given++
 		}
 	}
-
-	payload := map[string]any{}
-	if def.RequiresTask {
-		if strings.TrimSpace(cmdOpts.task) == "" {
-			return nil, errors.New("missing --task (or provide JSON via --input or stdin)")
+	if given > 1 {
+		return nil, errors.New("use only one of --input, --input-text and --stdin")
+	}
+	switch {
+	case cmdOpts.stdin || cmdOpts.input == "-":
+		return readStdinInput(opts)
+	case strings.HasPrefix(cmdOpts.input, "@"):
+		data, err := os.ReadFile(strings.TrimPrefix(cmdOpts.input, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("--input: %w", err)
 		}
-		payload["task"] = strings.TrimSpace(cmdOpts.task)
+		return data, nil
+	case cmdOpts.input != "":
+		return []byte(cmdOpts.input), nil
+	case cmdOpts.inputText != "":
+		return []byte(cmdOpts.inputText), nil
 	}
-	if cmdOpts.status != "" {
-		payload["status"] = cmdOpts.status
+
+	if def.RequiresTask && strings.TrimSpace(cmdOpts.task) == "" {
+		return nil, errors.New("missing --task (or provide JSON via --input or stdin)")
 	}
-	if len(payload) == 0 {
-		return nil, nil
+	return actionPayload(def, cmdOpts.task, cmdOpts.status)
+}
+
+func readStdinInput(opts *rootOptions) ([]byte, error) {
+	if opts != nil && opts.stdinReserved {
+		return nil, errors.New("stdin is not available here; use --input")
 	}
-	data, err := json.Marshal(payload)
+	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		return nil, err
 	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("no input on stdin")
+	}
 	return data, nil
 }
 
+func actionTask(cmdOpts *actionCmdOptions, input []byte) string {
+	if task := strings.TrimSpace(cmdOpts.task); task != "" {
+		return task
+	}
+	return taskFromInput(string(input))
+}
+
 func taskFromInput(raw string) string {
 	if strings.TrimSpace(raw) == "" {
 		return ""
@@ -186,29 +244,3 @@ func taskFromInput(raw string) string {
 	}
 	return ""
 }
-
-func printDryRun(opts *rootOptions, shortcut string, input []byte) error {
-	payload := map[string]any{
-		"dry_run":  true,
-		"shortcut": shortcut,
-	}
-	if input != nil {
-		var parsed any
-		if err := json.Unmarshal(input, &parsed); err == nil {
-			payload["input"] = parsed
-		} else {
-			payload["input"] = string(input)
-		}
-	}
-	if opts != nil {
-		if opts.isAgent() {
-			return output.PrintJSON(os.Stdout, payload, false)
-		}
-	}
-	if input != nil {
-		fmt.Fprintf(os.Stdout, "Dry run: %s %s\n", shortcut, string(input))
-		return nil
-	}
-	fmt.Fprintf(os.Stdout, "Dry run: %s\n", shortcut)
-	return nil
-}
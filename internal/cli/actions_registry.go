@@ -12,8 +12,13 @@ func availableActionDefs() []discovery.ActionDef {
 	if os.Getenv(envDisableDiscovery) != "" {
 		return defs
 	}
-	ctx := context.Background()
-	disc, err := discovery.Discover(ctx)
+	disc, err := discovery.Discover(context.Background())
+	return actionDefsFromDiscovery(defs, disc, err)
+}
+
+// actionDefsFromDiscovery keeps the actions discovery found, falling back to
+// every default definition when discovery failed or found nothing.
+func actionDefsFromDiscovery(defs []discovery.ActionDef, disc discovery.Discovery, err error) []discovery.ActionDef {
 	if err != nil || len(disc.Actions) == 0 {
 		return defs
 	}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"streaks-cli/internal/config"
 	"streaks-cli/internal/discovery"
@@ -17,7 +20,7 @@ import (
 func TestBuildActionInputFromFlags(t *testing.T) {
 	def := discovery.ActionDef{ID: "task-complete", RequiresTask: true}
 	opts := &actionCmdOptions{task: "Read", status: "All"}
-	data, err := buildActionInput(def, opts)
+	data, err := buildActionInput(def, opts, nil)
 	if err != nil {
 		t.Fatalf("buildActionInput: %v", err)
 	}
@@ -32,7 +35,7 @@ func TestBuildActionInputFromFlags(t *testing.T) {
 
 func TestBuildActionInputFromStdin(t *testing.T) {
 	def := discovery.ActionDef{ID: "task-list", RequiresTask: false}
-	opts := &actionCmdOptions{}
+	opts := &actionCmdOptions{stdin: true}
 	input := []byte(`{"ok":true}`)
 
 	orig := os.Stdin
@@ -45,7 +48,7 @@ func TestBuildActionInputFromStdin(t *testing.T) {
 		_ = r.Close()
 	}()
 
-	data, err := buildActionInput(def, opts)
+	data, err := buildActionInput(def, opts, nil)
 	if err != nil {
 		t.Fatalf("buildActionInput: %v", err)
 	}
@@ -54,6 +57,50 @@ func TestBuildActionInputFromStdin(t *testing.T) {
 	}
 }
 
+func TestBuildActionInputSources(t *testing.T) {
+	def := discovery.ActionDef{ID: "task-complete", RequiresTask: true}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(path, []byte(`{"task":"Read"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := buildActionInput(def, &actionCmdOptions{input: "@" + path}, nil)
+	if err != nil || string(data) != `{"task":"Read"}` {
+		t.Fatalf("@file: %s (%v)", data, err)
+	}
+	data, err = buildActionInput(def, &actionCmdOptions{inputText: "hello"}, nil)
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("--input-text: %s (%v)", data, err)
+	}
+	if _, err := buildActionInput(def, &actionCmdOptions{input: "{}", inputText: "x"}, nil); err == nil {
+		t.Fatalf("expected --input and --input-text to conflict")
+	}
+	if _, err := buildActionInput(def, &actionCmdOptions{task: "Read", inputFiles: []string{filepath.Join(dir, "missing.png")}}, nil); err == nil {
+		t.Fatalf("expected a missing --input-file error")
+	}
+	if _, err := buildActionInput(def, &actionCmdOptions{input: "-"}, &rootOptions{stdinReserved: true}); err == nil {
+		t.Fatalf("expected stdin to be unavailable")
+	}
+
+	// A non-interactive, empty stdin is left alone unless asked for.
+	orig := os.Stdin
+	r, w, _ := os.Pipe()
+	_ = w.Close()
+	os.Stdin = r
+	defer func() {
+		os.Stdin = orig
+		_ = r.Close()
+	}()
+	data, err = buildActionInput(def, &actionCmdOptions{task: "Read"}, nil)
+	if err != nil || string(data) != `{"task":"Read"}` {
+		t.Fatalf("--task with closed stdin: %s (%v)", data, err)
+	}
+	if _, err := buildActionInput(def, &actionCmdOptions{stdin: true}, nil); err == nil {
+		t.Fatalf("expected an empty stdin error with --stdin")
+	}
+}
+
 func TestRunActionCommandUsesExplicitShortcut(t *testing.T) {
 	origRun := runShortcut
 	origDiscover := discover
@@ -73,7 +120,7 @@ func TestRunActionCommandUsesExplicitShortcut(t *testing.T) {
 	discover = func(_ context.Context) (discovery.Discovery, error) {
 		return discovery.Discovery{}, nil
 	}
-	listShortcuts = func(_ context.Context) ([]shortcuts.Shortcut, error) {
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
 		return nil, nil
 	}
 
@@ -127,7 +174,7 @@ func TestRunActionCommandUsesDirectShortcut(t *testing.T) {
 			},
 		}, nil
 	}
-	listShortcuts = func(_ context.Context) ([]shortcuts.Shortcut, error) {
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
 		return []shortcuts.Shortcut{{Name: "All Tasks"}}, nil
 	}
 
@@ -163,7 +210,7 @@ func TestRunActionCommandUsesMapping(t *testing.T) {
 			},
 		}, nil
 	}
-	listShortcuts = func(_ context.Context) ([]shortcuts.Shortcut, error) {
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
 		return []shortcuts.Shortcut{{Name: "All Tasks"}}, nil
 	}
 
@@ -188,3 +235,680 @@ func TestRunActionCommandUsesMapping(t *testing.T) {
 		t.Fatalf("expected mapped shortcut, got %s", called)
 	}
 }
+
+func TestRunActionCommandRecordsJournalNote(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+	runShortcut = func(_ context.Context, _ string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		return []byte("done"), nil
+	}
+
+	dir := t.TempDir()
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("STREAKS_CLI_JOURNAL", "")
+	def := discovery.ActionDef{ID: "task-complete", Title: "Mark task complete", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	cmdOpts := &actionCmdOptions{task: "Read", note: "read ch. 4", shortcut: "Complete Task"}
+	if err := runActionCommand(context.Background(), def, cmdOpts, &rootOptions{noOutput: true}); err != nil {
+		t.Fatalf("runActionCommand: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "journal.jsonl"))
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(data), &entry); err != nil {
+		t.Fatalf("unmarshal journal: %v", err)
+	}
+	if entry["task"] != "Read" || entry["note"] != "read ch. 4" || entry["shortcut"] != "Complete Task" {
+		t.Fatalf("unexpected journal entry: %v", entry)
+	}
+}
+
+func TestJournalFailureIsAWarning(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+	runs := 0
+	runShortcut = func(_ context.Context, _ string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		runs++
+		return []byte("done"), nil
+	}
+	dir := t.TempDir()
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(dir, "config.json"))
+	// A directory cannot be appended to, so the journal write fails.
+	t.Setenv("STREAKS_CLI_JOURNAL", dir)
+
+	out := captureCommand(t, "--agent", "task-complete", "--task", "Read", "--note", "read ch. 4", "--shortcut", "Complete Task")
+	var envelope actionEnvelope
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if runs != 1 || !envelope.OK || len(envelope.Warnings) != 1 || !strings.HasPrefix(envelope.Warnings[0], "journal write failed: ") {
+		t.Fatalf("journal failure should be a warning on a successful run: %+v", envelope)
+	}
+}
+
+func TestRunActionCommandRepeatsWithCount(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+
+	calls := 0
+	runShortcut = func(_ context.Context, _ string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		calls++
+		if calls == 3 {
+			return nil, errors.New("boom")
+		}
+		return []byte(`{"ok":true}`), nil
+	}
+
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-complete", Title: "Mark task complete", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	cmdOpts := &actionCmdOptions{task: "Drink water", shortcut: "Complete Task", count: 5}
+
+	origStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := runActionCommand(context.Background(), def, cmdOpts, &rootOptions{agent: true})
+	_ = w.Close()
+	os.Stdout = origStdout
+	out, _ := io.ReadAll(r)
+	_ = r.Close()
+
+	if code, _ := exitCodeFromError(err); code != ExitCodeActionFailed {
+		t.Fatalf("expected action failure, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected to stop after the failing run, got %d calls", calls)
+	}
+	var envelope actionEnvelope
+	if err := json.Unmarshal(bytes.TrimSpace(out), &envelope); err != nil {
+		t.Fatalf("unmarshal envelope: %v (%s)", err, out)
+	}
+	if envelope.OK || envelope.Count != 5 || envelope.Completed != 2 || len(envelope.Iterations) != 3 {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+	if envelope.Iterations[2].OK || envelope.Iterations[2].Error == "" {
+		t.Fatalf("expected failing iteration, got %+v", envelope.Iterations[2])
+	}
+}
+
+func TestRunActionCommandStatusCheck(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+
+	statusOutput := ""
+	runShortcut = func(_ context.Context, _ string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		return []byte(statusOutput), nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-status", Title: "Task status", Transport: discovery.TransportShortcuts, RequiresTask: true}
+
+	cases := []struct {
+		output string
+		expect string
+		code   int
+	}{
+		{`{"is_complete":"Yes"}`, "", 0},
+		{"Is Complete: No", "", ExitCodeCheckFailed},
+		{"Is Complete: No", "pending", 0},
+		{`{"is_missed":true}`, "missed", 0},
+		{"???", "done", ExitCodeStatusUnknown},
+		{"Mission is due today", "pending", ExitCodeStatusUnknown},
+		{"Title: Mission\nNotes: due soon", "", ExitCodeStatusUnknown},
+	}
+	for _, tc := range cases {
+		statusOutput = tc.output
+		cmdOpts := &actionCmdOptions{task: "Gym", shortcut: "Get Task", check: true, expect: tc.expect}
+		err := runActionCommand(context.Background(), def, cmdOpts, &rootOptions{noOutput: true})
+		code, _ := exitCodeFromError(err)
+		if err != nil && code == 0 {
+			t.Fatalf("%q/%q: unexpected error %v", tc.output, tc.expect, err)
+		}
+		if code != tc.code {
+			t.Fatalf("%q/%q: expected exit %d, got %d (%v)", tc.output, tc.expect, tc.code, code, err)
+		}
+		if err != nil && !isSilentExit(err) {
+			t.Fatalf("%q/%q: expected silent exit, got %v", tc.output, tc.expect, err)
+		}
+	}
+
+	err := runActionCommand(context.Background(), def, &actionCmdOptions{task: "Gym", expect: "later"}, &rootOptions{})
+	if code, _ := exitCodeFromError(err); code != ExitCodeUsage {
+		t.Fatalf("expected usage error for invalid --expect, got %v", err)
+	}
+}
+
+func TestExecuteGuardErrors(t *testing.T) {
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	cases := []struct {
+		req  actionRequest
+		want string
+	}{
+		{actionRequest{Action: "task-complete", Input: json.RawMessage(`{"note":"no task"}`), IfPending: true}, "missing task for --if-pending/--unless-done"},
+		{actionRequest{Action: "task-list", UnlessDone: true}, "guards are only supported for task actions (task-list)"},
+	}
+	for _, tc := range cases {
+		_, err := newActionSession().execute(context.Background(), tc.req, &rootOptions{noOutput: true})
+		if code, _ := exitCodeFromError(err); code != ExitCodeUsage || err.Error() != tc.want {
+			t.Fatalf("%s: got %v, want usage error %q", tc.req.Action, err, tc.want)
+		}
+	}
+}
+
+func TestRunActionCommandGuardSkipsWhenDone(t *testing.T) {
+	origRun := runShortcut
+	origDiscover := discover
+	origList := listShortcuts
+	defer func() {
+		runShortcut = origRun
+		discover = origDiscover
+		listShortcuts = origList
+	}()
+
+	var called []string
+	runShortcut = func(_ context.Context, name string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		called = append(called, name)
+		if name == "Get Task" {
+			return []byte(`{"is_complete":"Yes"}`), nil
+		}
+		return []byte("ok"), nil
+	}
+	discover = func(_ context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Complete Task"}, {Name: "Get Task"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-complete", Title: "Mark task complete", Transport: discovery.TransportShortcuts, RequiresTask: true}
+
+	origStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := runActionCommand(context.Background(), def, &actionCmdOptions{task: "Read", unlessDone: true}, &rootOptions{agent: true})
+	_ = w.Close()
+	os.Stdout = origStdout
+	out, _ := io.ReadAll(r)
+	_ = r.Close()
+
+	if err != nil {
+		t.Fatalf("runActionCommand: %v", err)
+	}
+	if len(called) != 1 || called[0] != "Get Task" {
+		t.Fatalf("expected only the status shortcut to run, got %v", called)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(out), &payload); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, out)
+	}
+	if payload["skipped"] != true || payload["reason"] == "" {
+		t.Fatalf("expected skipped envelope, got %v", payload)
+	}
+
+	called = nil
+	if err := runActionCommand(context.Background(), def, &actionCmdOptions{task: "Read", ifPending: true, dryRun: true}, &rootOptions{noOutput: true}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(called) != 0 {
+		t.Fatalf("dry run should not run shortcuts, got %v", called)
+	}
+}
+
+func TestDryRunPlan(t *testing.T) {
+	origDiscover, origList := discover, listShortcuts
+	defer func() { discover, listShortcuts = origDiscover, origList }()
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return nil, errors.New("shortcuts list failed")
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	opts := &rootOptions{timeout: 30 * time.Second, retries: 2, retryWait: time.Second}
+	cmdOpts := &actionCmdOptions{task: "Read"}
+
+	resolution, err := newActionSession().resolve(context.Background(), def, cmdOpts, opts)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	plan, err := buildDryRunPlan(def.ID, resolution, []byte(`{"task":"Read"}`), cmdOpts, opts)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Shortcut != "Complete Read" || plan.Match != "" || plan.ListError == "" {
+		t.Fatalf("unexpected resolution in plan: %+v", plan)
+	}
+	if strings.Join(plan.Fallbacks, ",") != "Complete Task" {
+		t.Fatalf("expected wrapper fallback, got %v", plan.Fallbacks)
+	}
+	if len(plan.Candidates) != 2 || plan.Candidates[0].Origin != discovery.OriginTitle || plan.Candidates[1].Origin != originWrapper {
+		t.Fatalf("unexpected candidates: %+v", plan.Candidates)
+	}
+	wantArgv := "/usr/bin/shortcuts run Complete Read --input-path <input.json> --output-path <output-dir> --output-type public.plain-text"
+	if strings.Join(plan.Argv, " ") != wantArgv {
+		t.Fatalf("argv = %v", plan.Argv)
+	}
+	if plan.TimeoutMS != 30000 || plan.Retries != 2 || plan.RetryDelayMS != 1000 {
+		t.Fatalf("unexpected run settings: %+v", plan)
+	}
+
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "complete read"}}, nil
+	}
+	resolution, err = newActionSession().resolve(context.Background(), def, cmdOpts, opts)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	plan, err = buildDryRunPlan(def.ID, resolution, nil, cmdOpts, opts)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.Shortcut != "complete read" || plan.Match != matchNormalized || len(plan.Fallbacks) != 0 {
+		t.Fatalf("unexpected matched plan: %+v", plan)
+	}
+}
+
+func TestResolveAction(t *testing.T) {
+	origDiscover, origList := discover, listShortcuts
+	defer func() { discover, listShortcuts = origDiscover, origList }()
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Complete Task", ID: "ABC-123"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true}
+
+	report, err := resolveAction(context.Background(), newActionSession(), def, "Read", nil)
+	if err != nil {
+		t.Fatalf("resolveAction: %v", err)
+	}
+	if len(report.Candidates) != 2 {
+		t.Fatalf("unexpected candidates: %+v", report.Candidates)
+	}
+	title, wrapper := report.Candidates[0], report.Candidates[1]
+	if title.Source != discovery.OriginTitle || title.Exists == nil || *title.Exists || title.Winner {
+		t.Fatalf("unexpected title candidate: %+v", title)
+	}
+	if wrapper.Source != originWrapper || !wrapper.Winner || wrapper.Match != matchExact {
+		t.Fatalf("wrapper alias should win: %+v", wrapper)
+	}
+	if report.Summary.Shortcut != "Complete Task" || report.Summary.Source != sourceDiscovery {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{"task-complete": {Name: "My Complete"}}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	report, err = resolveAction(context.Background(), newActionSession(), def, "Read", nil)
+	if err != nil {
+		t.Fatalf("resolveAction: %v", err)
+	}
+	first := report.Candidates[0]
+	if first.Source != sourceMapping || !first.Winner || *first.Exists || len(report.Candidates) != 3 {
+		t.Fatalf("mapping should win even when missing: %+v", report.Candidates)
+	}
+	if !strings.Contains(report.Summary.Reason, "not found") {
+		t.Fatalf("expected missing mapping warning, got %q", report.Summary.Reason)
+	}
+}
+
+func TestResolveStrategies(t *testing.T) {
+	origDiscover, origList := discover, listShortcuts
+	defer func() { discover, listShortcuts = origDiscover, origList }()
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{
+			App:           discovery.AppInfo{Name: "Streaks"},
+			AppIntentKeys: []discovery.AppIntentKey{{Key: "AppIntent.CompleteTask.Title", Value: "Complete Streak", Locale: "en"}},
+		}, nil
+	}
+	library := []shortcuts.Shortcut{{Name: "Complete Read"}, {Name: "Complete Streak"}, {Name: "Complete Task"}, {Name: "Backup Complete"}}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) { return library, nil }
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true, Keys: []string{"AppIntent.CompleteTask.Title"}}
+	cmdOpts := &actionCmdOptions{task: "Read"}
+
+	want := map[string]string{
+		config.StrategyAuto:          "Complete Read",
+		config.StrategyWrappersFirst: "Complete Task",
+		config.StrategyIntentsFirst:  "Complete Streak",
+	}
+	for strategy, shortcut := range want {
+		resolution, err := newActionSession().resolve(context.Background(), def, cmdOpts, &rootOptions{strategy: strategy})
+		if err != nil {
+			t.Fatalf("%s: %v", strategy, err)
+		}
+		if resolution.Shortcut != shortcut || resolution.Strategy != strategy {
+			t.Fatalf("%s: got %s (%s), want %s", strategy, resolution.Shortcut, resolution.Strategy, shortcut)
+		}
+	}
+
+	_, err := newActionSession().resolve(context.Background(), def, cmdOpts, &rootOptions{strategy: config.StrategyMappingOnly})
+	if code, _ := exitCodeFromError(err); code != ExitCodeShortcutMissing {
+		t.Fatalf("mapping-only without mapping should fail with shortcut_missing, got %v", err)
+	}
+
+	if _, err := config.Write(config.Config{
+		Prefer: config.StrategyMappingOnly,
+		Mappings: map[string]config.ShortcutRef{"task-complete": {
+			Name:      "Missing Shortcut",
+			Fallbacks: []config.ShortcutRef{{Name: "Also Missing"}, {Name: "Backup Complete"}},
+		}},
+	}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	resolution, err := newActionSession().resolve(context.Background(), def, cmdOpts, nil)
+	if err != nil {
+		t.Fatalf("resolve with fallbacks: %v", err)
+	}
+	if resolution.Shortcut != "Backup Complete" || resolution.Source != sourceMapping || resolution.Strategy != config.StrategyMappingOnly {
+		t.Fatalf("expected mapping fallback from config strategy, got %+v", resolution)
+	}
+
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) { return nil, errors.New("list failed") }
+	resolution, err = newActionSession().resolve(context.Background(), def, cmdOpts, nil)
+	if err != nil {
+		t.Fatalf("resolve without list: %v", err)
+	}
+	if resolution.Shortcut != "" || strings.Join(resolution.Candidates, ",") != "Missing Shortcut,Also Missing,Backup Complete" {
+		t.Fatalf("fallbacks should be tried in order at run time, got %+v", resolution)
+	}
+}
+
+func TestResolveShortcutFlagIgnoresStrategy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	t.Setenv("STREAKS_CLI_CONFIG", path)
+	if err := os.WriteFile(path, []byte(`{"prefer":"bogus"}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	for _, opts := range []*rootOptions{nil, {strategy: "bogus"}} {
+		resolution, err := newActionSession().resolve(context.Background(), def, &actionCmdOptions{task: "Read", shortcut: "My Shortcut"}, opts)
+		if err != nil {
+			t.Fatalf("--shortcut should not depend on the strategy: %v", err)
+		}
+		if resolution.Shortcut != "My Shortcut" || resolution.Source != sourceFlag || resolution.Strategy != "" {
+			t.Fatalf("unexpected resolution: %+v", resolution)
+		}
+	}
+	_, err := newActionSession().resolve(context.Background(), def, &actionCmdOptions{task: "Read"}, nil)
+	if code, _ := exitCodeFromError(err); code != ExitCodeUsage {
+		t.Fatalf("an invalid strategy should still fail normal resolution, got %v", err)
+	}
+}
+
+func TestTaskMappingTakesPrecedence(t *testing.T) {
+	origDiscover, origList := discover, listShortcuts
+	defer func() { discover, listShortcuts = origDiscover, origList }()
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Log Run"}, {Name: "Complete Task"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	for _, args := range [][]string{
+		{"link", "task-complete", "--shortcut", "Complete Task", "--no-output"},
+		{"link", "task-complete", "--task", "Run 5k", "--shortcut", "Log Run", "--no-output"},
+	} {
+		cmd := newRootCmd()
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	resolve := func(task string) shortcutResolution {
+		t.Helper()
+		resolution, err := newActionSession().resolve(context.Background(), def, &actionCmdOptions{task: task}, nil)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", task, err)
+		}
+		return resolution
+	}
+	if got := resolve("run 5K"); got.Shortcut != "Log Run" || got.Source != sourceTaskMapping {
+		t.Fatalf("task mapping should win, got %+v", got)
+	}
+	if got := resolve("Read"); got.Shortcut != "Complete Task" || got.Source != sourceMapping {
+		t.Fatalf("other tasks should use the action mapping, got %+v", got)
+	}
+
+	report, err := resolveAction(context.Background(), newActionSession(), def, "Run 5k", nil)
+	if err != nil {
+		t.Fatalf("resolveAction: %v", err)
+	}
+	if first := report.Candidates[0]; first.Source != sourceTaskMapping || !first.Winner {
+		t.Fatalf("task mapping should be listed first and win: %+v", report.Candidates)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"unlink", "task-complete", "--task", "RUN 5K", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+	if got := resolve("Run 5k"); got.Shortcut != "Complete Task" {
+		t.Fatalf("unlink --task should leave the action mapping, got %+v", got)
+	}
+}
+
+func TestMappingInputAdapter(t *testing.T) {
+	origRun, origDiscover, origList := runShortcut, discover, listShortcuts
+	defer func() { runShortcut, discover, listShortcuts = origRun, origDiscover, origList }()
+	var sent []string
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		if name == "Missing" {
+			return nil, errors.New("couldn't find shortcut")
+		}
+		sent = append(sent, name+"="+string(input))
+		return []byte(`{"ok":true}`), nil
+	}
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Log Task"}}, nil
+	}
+	dir := t.TempDir()
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(dir, "config.json"))
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"link", "task-complete", "--shortcut", "Log Task", "--input", "text:{{.task}}", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	def := discovery.ActionDef{ID: "task-complete", Title: "Complete ${task}", Transport: discovery.TransportShortcuts, RequiresTask: true}
+	trace := filepath.Join(dir, "trace.ndjson")
+	cmdOpts := &actionCmdOptions{task: "Read", trace: trace}
+	if err := runActionCommand(context.Background(), def, cmdOpts, &rootOptions{noOutput: true}); err != nil {
+		t.Fatalf("runActionCommand: %v", err)
+	}
+	if strings.Join(sent, ",") != "Log Task=Read" {
+		t.Fatalf("expected adapted text input, got %v", sent)
+	}
+	data, err := os.ReadFile(trace)
+	if err != nil {
+		t.Fatalf("read trace: %v", err)
+	}
+	var entry traceEntry
+	if err := json.Unmarshal(data, &entry); err != nil || string(entry.Input) != `"Read"` {
+		t.Fatalf("trace should record the adapted input, got %s (%v)", data, err)
+	}
+
+	resolution, err := newActionSession().resolve(context.Background(), def, cmdOpts, nil)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	plan, err := buildDryRunPlan(def.ID, resolution, []byte(`{"task":"Read"}`), cmdOpts, nil)
+	if err != nil || plan.Input != "Read" || plan.InputAdapter != "text:{{.task}}" {
+		t.Fatalf("dry run should show the adapted input, got %+v (%v)", plan, err)
+	}
+
+	// Fallbacks carry their own adapters.
+	rename, _ := config.ParseInputAdapter("rename:task=name")
+	cfg, _, _ := config.Load()
+	cfg.Mappings[def.ID] = config.ShortcutRef{Name: "Missing", Fallbacks: []config.ShortcutRef{{Name: "Other", Input: &rename}}}
+	if _, err := config.Write(cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	sent = nil
+	if err := runActionCommand(context.Background(), def, &actionCmdOptions{task: "Read"}, &rootOptions{noOutput: true}); err != nil {
+		t.Fatalf("runActionCommand: %v", err)
+	}
+	if strings.Join(sent, ",") != `Other={"name":"Read"}` {
+		t.Fatalf("expected renamed input for the fallback, got %v", sent)
+	}
+}
+
+func TestActionOutputDefaults(t *testing.T) {
+	want := map[string]string{
+		"task-list":   "public.json lines",
+		"task-status": "public.json",
+		"export-all":  "public.zip-archive",
+		"export-task": "public.comma-separated-values-text",
+	}
+	for _, def := range discovery.DefaultActionDefinitions() {
+		spec, err := parseActionOutput(def)
+		if err != nil {
+			t.Fatalf("%s: %v", def.ID, err)
+		}
+		got := spec.Type
+		if spec.Extract != nil {
+			got += " " + spec.Extract.String()
+		}
+		if got != want[def.ID] {
+			t.Fatalf("%s: output default %q, want %q", def.ID, got, want[def.ID])
+		}
+	}
+	if _, err := parseActionOutput(discovery.ActionDef{ID: "broken", Extract: "bogus"}); err == nil {
+		t.Fatalf("expected an invalid default extractor to fail")
+	}
+}
+
+func TestDefaultExtractorWithoutMapping(t *testing.T) {
+	origRun, origDiscover, origList := runShortcut, discover, listShortcuts
+	defer func() { runShortcut, discover, listShortcuts = origRun, origDiscover, origList }()
+	var outputType string
+	runShortcut = func(_ context.Context, _ string, _ []byte, runOpts shortcuts.RunOptions) ([]byte, error) {
+		outputType = runOpts.OutputType
+		return []byte(`["Read","Gym"]`), nil
+	}
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Task List"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	out := captureCommand(t, "--agent", "task-list")
+	var envelope struct {
+		Result    []string `json:"result"`
+		ResultRaw string   `json:"result_raw"`
+	}
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if strings.Join(envelope.Result, ",") != "Read,Gym" || envelope.ResultRaw != `["Read","Gym"]` {
+		t.Fatalf("default extractor should fill result and result_raw: %s", out)
+	}
+	if outputType != "public.json" {
+		t.Fatalf("task-list should request public.json by default, got %q", outputType)
+	}
+}
+
+func TestMappingOutputExtractor(t *testing.T) {
+	origRun, origDiscover, origList := runShortcut, discover, listShortcuts
+	defer func() { runShortcut, discover, listShortcuts = origRun, origDiscover, origList }()
+	var outputTypes []string
+	runShortcut = func(_ context.Context, name string, _ []byte, runOpts shortcuts.RunOptions) ([]byte, error) {
+		outputTypes = append(outputTypes, runOpts.OutputType)
+		if name == "Task Dictionary" {
+			return []byte(`{"result":{"title":"Read","streak":4}}`), nil
+		}
+		return []byte("Read\nGym\n"), nil
+	}
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return nil, errors.New("no list")
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"link", "task-status", "--shortcut", "Task Dictionary", "--output-type", "public.json", "--extract", "path:result.streak", "--force", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+
+	run := func(actionID string, opts *rootOptions) actionEnvelope {
+		t.Helper()
+		def, _ := defaultActionDef(actionID)
+		cmdOpts := &actionCmdOptions{task: "Read"}
+		input, _ := actionPayload(def, "Read", "")
+		resolution, err := newActionSession().resolve(context.Background(), def, cmdOpts, opts)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", actionID, err)
+		}
+		name, result, err := runResolvedShortcut(context.Background(), actionID, resolution, input, cmdOpts, opts)
+		if err != nil {
+			t.Fatalf("run %s: %v", actionID, err)
+		}
+		return buildActionEnvelope(actionID, name, input, result)
+	}
+
+	envelope := run("task-status", &rootOptions{})
+	if envelope.Result != float64(4) || envelope.ResultRaw != `{"result":{"title":"Read","streak":4}}` {
+		t.Fatalf("unexpected extracted result: %#v raw %q", envelope.Result, envelope.ResultRaw)
+	}
+	envelope = run("task-list", &rootOptions{})
+	if list, ok := envelope.Result.([]string); !ok || strings.Join(list, ",") != "Read,Gym" || envelope.ResultRaw != "Read\nGym\n" {
+		t.Fatalf("task-list should split lines by default: %#v raw %q", envelope.Result, envelope.ResultRaw)
+	}
+	run("task-status", &rootOptions{shortcutsOutput: "public.rtf"})
+	if strings.Join(outputTypes, ",") != "public.json,public.json,public.rtf" {
+		t.Fatalf("unexpected output types: %v", outputTypes)
+	}
+
+	cfg, _, _ := config.Load()
+	extractor, _ := config.ParseExtractor("path:missing")
+	ref := cfg.Mappings["task-status"]
+	ref.Extract = &extractor
+	cfg.Mappings["task-status"] = ref
+	if _, err := config.Write(cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	envelope = run("task-status", &rootOptions{})
+	if envelope.ResultRaw != "" || len(envelope.Warnings) != 1 {
+		t.Fatalf("failed extraction should keep the normal result and warn: %+v", envelope)
+	}
+}
+
+func TestExportSave(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+	var got shortcuts.RunOptions
+	runShortcut = func(_ context.Context, _ string, _ []byte, opts shortcuts.RunOptions) ([]byte, error) {
+		got = opts
+		return []byte(`{"path":"/tmp/Read.csv","mime":"text/csv","size":10,"sha256":"ab"}`), nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	cmd := buildRootCmd(&rootOptions{}, discovery.DefaultActionDefinitions())
+	cmd.SetArgs([]string{"--output-dir", "/tmp/outputs", "export-task", "--task", "Read", "--shortcut", "Export Read", "--save", "/tmp/read.csv", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("export-task: %v", err)
+	}
+	if got.SavePath != "/tmp/read.csv" || got.OutputDir != "/tmp/outputs" {
+		t.Fatalf("unexpected run options: %+v", got)
+	}
+
+	cmd = buildRootCmd(&rootOptions{}, discovery.DefaultActionDefinitions())
+	cmd.SetArgs([]string{"task-list", "--save", "/tmp/list.txt"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("--save should only be offered by export actions")
+	}
+}
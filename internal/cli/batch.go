@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/output"
+)
+
+type batchOptions struct {
+	stopOnError  bool
+	continueOnly bool
+	dryRun       bool
+	trace        string
+}
+
+type actionSummary struct {
+	Summary bool   `json:"summary"`
+	Routine string `json:"routine,omitempty"`
+	Script  string `json:"script,omitempty"`
+	Total   int    `json:"total"`
+	OK      int    `json:"ok"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+	NotRun  int    `json:"not_run"`
+	Stopped bool   `json:"stopped,omitempty"`
+}
+
+func newBatchCmd(opts *rootOptions) *cobra.Command {
+	batchOpts := &batchOptions{}
+	cmd := &cobra.Command{
+		Use:   "batch [file]",
+		Short: "Run actions from NDJSON lines (stdin or file), one envelope per line",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if batchOpts.stopOnError && batchOpts.continueOnly {
+				return exitError(ExitCodeUsage, fmt.Errorf("--stop-on-error and --continue are mutually exclusive"))
+			}
+			in := io.Reader(os.Stdin)
+			if opts.stdinReserved && (len(args) == 0 || args[0] == "-") {
+				return exitError(ExitCodeUsage, fmt.Errorf("batch needs a file argument here; stdin is in use"))
+			}
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return exitError(ExitCodeUsage, err)
+				}
+				defer f.Close()
+				in = f
+			}
+			summary, err := runBatch(context.Background(), opts.actionSession(), in, os.Stdout, batchOpts, opts)
+			if err != nil {
+				return err
+			}
+			if summary.Failed > 0 {
+				return exitError(ExitCodeActionFailed, fmt.Errorf("%d of %d batch actions failed", summary.Failed, summary.Total))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&batchOpts.stopOnError, "stop-on-error", false, "Stop at the first failing line (default)")
+	cmd.Flags().BoolVar(&batchOpts.continueOnly, "continue", false, "Keep running remaining lines after a failure")
+	cmd.Flags().BoolVar(&batchOpts.dryRun, "dry-run", false, "Resolve every line without running shortcuts")
+	cmd.Flags().StringVar(&batchOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
+	return cmd
+}
+
+// runBatch executes NDJSON requests in order against a single session so that
+// discovery and the shortcut list are only loaded once.
+func runBatch(ctx context.Context, session *actionSession, in io.Reader, out io.Writer, batchOpts *batchOptions, opts *rootOptions) (actionSummary, error) {
+	summary := actionSummary{Summary: true}
+	emit := func(v any) error {
+		if opts.noOutput {
+			return nil
+		}
+		return output.PrintJSON(out, v, false)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		summary.Total++
+		if summary.Stopped {
+			summary.NotRun++
+			continue
+		}
+		var req actionRequest
+		var envelope actionEnvelope
+		var runErr error
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			runErr = exitError(ExitCodeUsage, fmt.Errorf("line %d: invalid JSON: %w", lineNo, err))
+			envelope = errorEnvelope(nil, "", runErr)
+		} else if strings.TrimSpace(req.Action) == "" {
+			runErr = exitError(ExitCodeUsage, fmt.Errorf("line %d: missing action", lineNo))
+			envelope = errorEnvelope(req.ID, "", runErr)
+		} else {
+			req.DryRun = req.DryRun || batchOpts.dryRun
+			if req.Trace == "" {
+				req.Trace = batchOpts.trace
+			}
+			envelope, runErr = session.execute(ctx, req, opts)
+		}
+		switch {
+		case runErr != nil:
+			summary.Failed++
+			summary.Stopped = !batchOpts.continueOnly
+		case envelope.Skipped:
+			summary.Skipped++
+		default:
+			summary.OK++
+		}
+		if err := emit(envelope); err != nil {
+			return summary, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, err
+	}
+	return summary, emit(summary)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestRunBatchEchoesIDsAndSummarizes(t *testing.T) {
+	origRun := runShortcut
+	origDiscover := discover
+	origList := listShortcuts
+	defer func() {
+		runShortcut = origRun
+		discover = origDiscover
+		listShortcuts = origList
+	}()
+
+	discoverCalls, listCalls := 0, 0
+	discover = func(_ context.Context) (discovery.Discovery, error) {
+		discoverCalls++
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
+		listCalls++
+		return []shortcuts.Shortcut{{Name: "Complete Task"}, {Name: "Task List"}}, nil
+	}
+	var ran []string
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name+" "+string(input))
+		return []byte("ok"), nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	in := strings.NewReader(strings.Join([]string{
+		`{"id":"a","action":"task-complete","task":"Read"}`,
+		`# comment`,
+		`{"id":2,"action":"nope"}`,
+		`{"id":"c","action":"task-complete","task":"Stretch"}`,
+		`{"id":"d","action":"task-list"}`,
+	}, "\n"))
+
+	var out bytes.Buffer
+	summary, err := runBatch(context.Background(), newActionSession(), in, &out, &batchOptions{continueOnly: true}, &rootOptions{})
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if discoverCalls != 1 || listCalls != 1 {
+		t.Fatalf("expected discovery and list once, got %d/%d", discoverCalls, listCalls)
+	}
+	if len(ran) != 3 || ran[0] != `Complete Task {"task":"Read"}` {
+		t.Fatalf("unexpected runs: %v", ran)
+	}
+	if summary.Total != 4 || summary.OK != 3 || summary.Failed != 1 || summary.NotRun != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 4 envelopes and a summary, got %d lines:\n%s", len(lines), out.String())
+	}
+	var second map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if second["id"] != float64(2) || second["ok"] != false || second["error_code"] != "usage" {
+		t.Fatalf("unexpected error envelope: %v", second)
+	}
+
+	ran = nil
+	out.Reset()
+	in = strings.NewReader(`{"id":"x","action":"nope"}` + "\n" + `{"id":"y","action":"task-list"}`)
+	summary, err = runBatch(context.Background(), newActionSession(), in, &out, &batchOptions{}, &rootOptions{})
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if len(ran) != 0 || summary.Failed != 1 || summary.NotRun != 1 || !summary.Stopped {
+		t.Fatalf("expected stop on first error, got ran=%v summary=%+v", ran, summary)
+	}
+}
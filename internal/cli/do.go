@@ -0,0 +1,398 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/output"
+)
+
+const defaultDoMinScore = 0.8
+
+type doOptions struct {
+	dryRun   bool
+	trace    string
+	minScore float64
+}
+
+type doInterpretation struct {
+	Action string  `json:"action"`
+	Task   string  `json:"task,omitempty"`
+	Phrase string  `json:"phrase"`
+	Score  float64 `json:"score"`
+}
+
+// doPhrase is a phrase template split around its ${task} slot and tokenized.
+type doPhrase struct {
+	action   string
+	template string
+	prefix   []string
+	suffix   []string
+	hasSlot  bool
+}
+
+func newDoCmd(opts *rootOptions) *cobra.Command {
+	doOpts := &doOptions{}
+	cmd := &cobra.Command{
+		Use:   "do <sentence>",
+		Short: "Run an action from a natural-language sentence using the app's own phrases",
+		Long: "Run an action from a natural-language sentence, e.g. st do \"mark reading as complete\".\n\n" +
+			"The sentence is matched against Streaks' localized App Shortcut phrases and action titles in the\n" +
+			"preferred locales; the ${task} slot is resolved against the task list.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if doOpts.minScore <= 0 || doOpts.minScore > 1 {
+				return exitError(ExitCodeUsage, fmt.Errorf("--min-score must be between 0 and 1"))
+			}
+			return runDo(context.Background(), opts.actionSession(), strings.Join(args, " "), doOpts, opts)
+		},
+	}
+	cmd.Flags().BoolVar(&doOpts.dryRun, "dry-run", false, "Print the parsed interpretation without running")
+	cmd.Flags().StringVar(&doOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
+	cmd.Flags().Float64Var(&doOpts.minScore, "min-score", defaultDoMinScore, "Minimum confidence (0-1) required to run")
+	return cmd
+}
+
+func runDo(ctx context.Context, session *actionSession, sentence string, doOpts *doOptions, opts *rootOptions) error {
+	phrases, appName := doPhrases(ctx, session)
+	var tasks []string
+	if list, err := session.invoke(ctx, "task-list", "", "", opts); err == nil {
+		tasks = parseTaskList(list.Result.Output)
+	}
+	ranked := interpretSentence(sentence, appName, phrases, tasks)
+	best, ok := confidentInterpretation(ranked, doOpts.minScore)
+	if !ok {
+		err := exitError(ExitCodeUsage, fmt.Errorf("could not confidently interpret %q%s", sentence, suggestionText(ranked)))
+		envelope := errorEnvelope(nil, "", err)
+		envelope.Suggestions = topInterpretations(ranked, 5)
+		if opts.isAgent() && !opts.noOutput {
+			if printErr := output.PrintJSON(os.Stdout, envelope, false); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	}
+
+	req := actionRequest{Action: best.Action, Task: best.Task, DryRun: doOpts.dryRun, Trace: doOpts.trace}
+	envelope, runErr := session.execute(ctx, req, opts)
+	envelope.Interpretation = &best
+	if doOpts.dryRun {
+		envelope.Suggestions = topInterpretations(ranked[1:], 4)
+	}
+	if opts.noOutput {
+		return runErr
+	}
+	if opts.isAgent() {
+		if err := output.PrintJSON(os.Stdout, envelope, false); err != nil {
+			return err
+		}
+		return runErr
+	}
+	if !opts.quiet || doOpts.dryRun {
+		fmt.Printf("Interpreted as: %s (phrase %q, score %.2f)\n", describeInterpretation(best), best.Phrase, best.Score)
+	}
+	if doOpts.dryRun {
+		fmt.Printf("Shortcut: %s\n", envelope.Shortcut.Name)
+		for _, alt := range envelope.Suggestions {
+			fmt.Printf("  also: %s (score %.2f)\n", describeInterpretation(alt), alt.Score)
+		}
+		return runErr
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if raw := strings.TrimSpace(envelope.ResultRaw); raw != "" {
+		fmt.Println(raw)
+		return nil
+	}
+	if result, ok := envelope.Result.(map[string]any); ok {
+		if raw, ok := result["raw"].(string); ok {
+			if raw != "" {
+				fmt.Println(raw)
+			}
+			return nil
+		}
+	}
+	if envelope.Result != nil {
+		return output.PrintJSON(os.Stdout, envelope.Result, false)
+	}
+	return nil
+}
+
+// doPhrases collects phrase templates for every Shortcuts action. Discovery
+// failures fall back to action titles so st do still works without the app.
+func doPhrases(ctx context.Context, session *actionSession) ([]doPhrase, string) {
+	var intentKeys, shortcutPhrases []discovery.AppIntentKey
+	appName := "Streaks"
+	if disc, err := session.discovery(ctx); err == nil {
+		intentKeys = disc.AppIntentKeys
+		shortcutPhrases = disc.AppShortcutPhrases
+		if disc.App.Name != "" {
+			appName = disc.App.Name
+		}
+	}
+	phrases := make([]doPhrase, 0)
+	for _, def := range discovery.DefaultActionDefinitions() {
+		if def.Transport != discovery.TransportShortcuts {
+			continue
+		}
+		for _, tmpl := range discovery.ActionPhraseTemplates(def, intentKeys, shortcutPhrases) {
+			if phrase, ok := parseDoPhrase(def, tmpl, appName); ok {
+				phrases = append(phrases, phrase)
+			}
+		}
+	}
+	return phrases, appName
+}
+
+func parseDoPhrase(def discovery.ActionDef, tmpl, appName string) (doPhrase, bool) {
+	phrase := doPhrase{action: def.ID, template: tmpl}
+	text := strings.ReplaceAll(tmpl, "%@", "${task}")
+	text = strings.ReplaceAll(text, "${applicationName}", appName)
+	before, after, hasSlot := strings.Cut(text, "${task}")
+	if hasSlot != def.RequiresTask {
+		return doPhrase{}, false
+	}
+	appTokens := phraseTokens(appName)
+	phrase.hasSlot = hasSlot
+	phrase.prefix = trimConnectors(removeTokens(phraseTokens(before), appTokens))
+	phrase.suffix = trimConnectors(removeTokens(phraseTokens(after), appTokens))
+	if len(phrase.prefix)+len(phrase.suffix) == 0 {
+		return doPhrase{}, false
+	}
+	return phrase, true
+}
+
+// interpretSentence scores every phrase against the sentence and returns the
+// best interpretation per action/task pair, highest score first.
+func interpretSentence(sentence, appName string, phrases []doPhrase, tasks []string) []doInterpretation {
+	tokens := trimConnectors(removeTokens(phraseTokens(sentence), phraseTokens(appName)))
+	best := make(map[string]doInterpretation)
+	order := make([]string, 0)
+	for _, phrase := range phrases {
+		interp, ok := matchDoPhrase(phrase, tokens, tasks)
+		if !ok {
+			continue
+		}
+		interp.Score = math.Round(interp.Score*100) / 100
+		key := interp.Action + "\x00" + strings.ToLower(interp.Task)
+		current, seen := best[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || interp.Score > current.Score {
+			best[key] = interp
+		}
+	}
+	ranked := make([]doInterpretation, 0, len(order))
+	for _, key := range order {
+		ranked = append(ranked, best[key])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+func matchDoPhrase(phrase doPhrase, tokens []string, tasks []string) (doInterpretation, bool) {
+	interp := doInterpretation{Action: phrase.action, Phrase: phrase.template}
+	if len(tokens) == 0 {
+		return interp, false
+	}
+	if !phrase.hasSlot {
+		interp.Score = tokenSimilarity(tokens, phrase.prefix)
+		return interp, interp.Score > 0
+	}
+
+	fixed := len(phrase.prefix) + len(phrase.suffix)
+	if len(tokens) > fixed && hasTokenPrefix(tokens, phrase.prefix) && hasTokenPrefix(reversed(tokens), reversed(phrase.suffix)) {
+		slot := tokens[len(phrase.prefix) : len(tokens)-len(phrase.suffix)]
+		task, score := resolveTaskSlot(slot, tasks)
+		interp.Task = task
+		interp.Score = score
+		return interp, score > 0
+	}
+
+	// Loose match: find a known task anywhere in the sentence and compare the
+	// remaining words with the fixed part of the phrase.
+	template := append(append([]string{}, phrase.prefix...), phrase.suffix...)
+	start, end, task := findTaskSpan(tokens, tasks)
+	if task == "" {
+		interp.Score = 0.5 * tokenSimilarity(tokens, template)
+		return interp, interp.Score > 0
+	}
+	rest := append(append([]string{}, tokens[:start]...), tokens[end:]...)
+	interp.Task = task
+	interp.Score = 0.9 * tokenSimilarity(rest, template)
+	return interp, interp.Score > 0
+}
+
+// resolveTaskSlot maps the words captured by ${task} to a known task name.
+// Without a task list the slot is used verbatim at reduced confidence.
+func resolveTaskSlot(slot []string, tasks []string) (string, float64) {
+	if len(tasks) == 0 {
+		return strings.Join(slot, " "), 0.85
+	}
+	bestTask, bestScore := "", 0.0
+	for _, task := range tasks {
+		score := 0.9 * tokenSimilarity(slot, phraseTokens(task))
+		if equalTokens(slot, phraseTokens(task)) {
+			score = 1
+		}
+		if score > bestScore {
+			bestTask, bestScore = task, score
+		}
+	}
+	return bestTask, bestScore
+}
+
+func findTaskSpan(tokens []string, tasks []string) (int, int, string) {
+	bestStart, bestEnd, bestTask := 0, 0, ""
+	for _, task := range tasks {
+		taskTokens := phraseTokens(task)
+		if len(taskTokens) == 0 || len(taskTokens) <= bestEnd-bestStart {
+			continue
+		}
+		for i := 0; i+len(taskTokens) <= len(tokens); i++ {
+			if equalTokens(tokens[i:i+len(taskTokens)], taskTokens) {
+				bestStart, bestEnd, bestTask = i, i+len(taskTokens), task
+				break
+			}
+		}
+	}
+	return bestStart, bestEnd, bestTask
+}
+
+// confidentInterpretation accepts the top interpretation when it clears the
+// threshold and is not tied with a different reading.
+func confidentInterpretation(ranked []doInterpretation, minScore float64) (doInterpretation, bool) {
+	if len(ranked) == 0 || ranked[0].Score < minScore {
+		return doInterpretation{}, false
+	}
+	if len(ranked) > 1 && ranked[0].Score-ranked[1].Score < 0.05 {
+		return doInterpretation{}, false
+	}
+	return ranked[0], true
+}
+
+func topInterpretations(ranked []doInterpretation, n int) []doInterpretation {
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+func suggestionText(ranked []doInterpretation) string {
+	top := topInterpretations(ranked, 3)
+	if len(top) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(top))
+	for _, interp := range top {
+		parts = append(parts, fmt.Sprintf("%s (%.2f)", describeInterpretation(interp), interp.Score))
+	}
+	return "; did you mean: " + strings.Join(parts, ", ")
+}
+
+func describeInterpretation(interp doInterpretation) string {
+	if interp.Task == "" {
+		return interp.Action
+	}
+	return fmt.Sprintf("%s %q", interp.Action, interp.Task)
+}
+
+// phraseConnectors are words left dangling once the app name is removed, e.g.
+// "in" from "Mark ${task} as complete in ${applicationName}".
+var phraseConnectors = map[string]struct{}{
+	"in": {}, "on": {}, "with": {}, "using": {}, "via": {}, "from": {},
+	"dans": {}, "avec": {}, "sur": {}, "en": {}, "mit": {}, "auf": {},
+	"con": {}, "su": {}, "da": {}, "em": {}, "op": {}, "met": {}, "med": {},
+}
+
+func phraseTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+func removeTokens(tokens, remove []string) []string {
+	if len(remove) == 0 {
+		return tokens
+	}
+	out := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if i+len(remove) <= len(tokens) && equalTokens(tokens[i:i+len(remove)], remove) {
+			i += len(remove) - 1
+			continue
+		}
+		out = append(out, tokens[i])
+	}
+	return out
+}
+
+func trimConnectors(tokens []string) []string {
+	for len(tokens) > 0 {
+		if _, ok := phraseConnectors[tokens[len(tokens)-1]]; !ok {
+			break
+		}
+		tokens = tokens[:len(tokens)-1]
+	}
+	for len(tokens) > 0 {
+		if _, ok := phraseConnectors[tokens[0]]; !ok {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	return tokens
+}
+
+// tokenSimilarity is the Dice coefficient over token multisets.
+func tokenSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(b))
+	for _, token := range b {
+		counts[token]++
+	}
+	common := 0
+	for _, token := range a {
+		if counts[token] > 0 {
+			counts[token]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+func hasTokenPrefix(tokens, prefix []string) bool {
+	return len(tokens) >= len(prefix) && equalTokens(tokens[:len(prefix)], prefix)
+}
+
+func equalTokens(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func reversed(tokens []string) []string {
+	out := make([]string, len(tokens))
+	for i, token := range tokens {
+		out[len(tokens)-1-i] = token
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/shortcuts"
+)
+
+func testDoPhrases(t *testing.T) []doPhrase {
+	t.Helper()
+	t.Setenv("LANG", "en_US.UTF-8")
+	phraseKeys := []discovery.AppIntentKey{
+		{Key: "#!SET#!_AppIntent.TaskComplete.Mark${task}AsComplete[0]", Value: "Mark ${task} as complete in ${applicationName}", Locale: "en"},
+		{Key: "#!SET#!_AppIntent.TaskMiss.Mark${task}AsMissed[0]", Value: "Mark ${task} as missed in ${applicationName}", Locale: "en"},
+		{Key: "#!SET#!_AppIntent.TaskComplete.Mark${task}AsComplete[0]", Value: "Marquer ${task} comme terminée dans ${applicationName}", Locale: "fr"},
+	}
+	var phrases []doPhrase
+	for _, def := range discovery.DefaultActionDefinitions() {
+		if def.Transport != discovery.TransportShortcuts {
+			continue
+		}
+		for _, tmpl := range discovery.ActionPhraseTemplates(def, nil, phraseKeys) {
+			if phrase, ok := parseDoPhrase(def, tmpl, "Streaks"); ok {
+				phrases = append(phrases, phrase)
+			}
+		}
+	}
+	return phrases
+}
+
+func TestInterpretSentence(t *testing.T) {
+	phrases := testDoPhrases(t)
+	tasks := []string{"Read", "Walk the Dog", "Meditate"}
+
+	cases := []struct {
+		sentence string
+		action   string
+		task     string
+	}{
+		{"Mark read as complete in Streaks", "task-complete", "Read"},
+		{"mark walk the dog as missed", "task-miss", "Walk the Dog"},
+		{"Marquer méditate comme terminée", "", ""},
+		{"start meditate timer", "timer-start", "Meditate"},
+		{"meditate status", "task-status", "Meditate"},
+		{"list tasks", "task-list", ""},
+	}
+	for _, tc := range cases {
+		ranked := interpretSentence(tc.sentence, "Streaks", phrases, tasks)
+		best, ok := confidentInterpretation(ranked, 0.6)
+		if tc.action == "" {
+			if ok && best.Score >= defaultDoMinScore {
+				t.Fatalf("%q: expected low confidence, got %+v", tc.sentence, best)
+			}
+			continue
+		}
+		if !ok || best.Action != tc.action || best.Task != tc.task {
+			t.Fatalf("%q: got %+v (ok=%v), ranked=%+v", tc.sentence, best, ok, ranked)
+		}
+	}
+
+	ranked := interpretSentence("please complete read", "Streaks", phrases, tasks)
+	if _, ok := confidentInterpretation(ranked, defaultDoMinScore); ok || ranked[0].Action != "task-complete" || ranked[0].Task != "Read" {
+		t.Fatalf("expected a low-confidence task-complete suggestion, got %+v", ranked)
+	}
+
+	ranked = interpretSentence("Marquer Meditate comme terminée dans Streaks", "Streaks", phrases, tasks)
+	if len(ranked) == 0 || ranked[0].Action != "task-complete" || ranked[0].Task != "Meditate" || ranked[0].Score != 1 {
+		t.Fatalf("expected localized phrase match, got %+v", ranked)
+	}
+}
+
+func TestRunDoLowConfidence(t *testing.T) {
+	origRun := runShortcut
+	origDiscover := discover
+	defer func() {
+		runShortcut = origRun
+		discover = origDiscover
+	}()
+	discover = func(_ context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{}, errors.New("no app")
+	}
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.json"))
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{
+		"task-list":     {Name: "List Tasks"},
+		"task-complete": {Name: "Complete Task"},
+	}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var ran []string
+	runShortcut = func(_ context.Context, name string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name)
+		if name == "List Tasks" {
+			return []byte("Read\nRun\n"), nil
+		}
+		return []byte("ok"), nil
+	}
+
+	opts := &rootOptions{noOutput: true}
+	err := runDo(context.Background(), newActionSession(), "water the plants", &doOptions{minScore: defaultDoMinScore}, opts)
+	if code, _ := exitCodeFromError(err); code != ExitCodeUsage {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+
+	err = runDo(context.Background(), newActionSession(), "mark read complete", &doOptions{minScore: defaultDoMinScore, dryRun: true}, opts)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if strings.Join(ran, ",") != "List Tasks,List Tasks" {
+		t.Fatalf("dry run should only list tasks, ran %v", ran)
+	}
+
+	err = runDo(context.Background(), newActionSession(), "mark read complete", &doOptions{minScore: defaultDoMinScore}, opts)
+	if err != nil || ran[len(ran)-1] != "Complete Task" {
+		t.Fatalf("expected Complete Task to run, err=%v ran=%v", err, ran)
+	}
+}
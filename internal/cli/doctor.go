@@ -15,17 +15,29 @@ import (
 )
 
 type doctorReport struct {
-	AppInstalled             bool     `json:"app_installed"`
-	AppPath                  string   `json:"app_path,omitempty"`
-	BundleID                 string   `json:"bundle_id,omitempty"`
-	Version                  string   `json:"version,omitempty"`
-	ShortcutsCLI             bool     `json:"shortcuts_cli"`
-	ShortcutsCLIPath         string   `json:"shortcuts_cli_path,omitempty"`
-	ShortcutCount            int      `json:"shortcut_count,omitempty"`
-	ShortcutActionsAvailable []string `json:"shortcut_actions_available,omitempty"`
-	ShortcutActionsMissing   []string `json:"shortcut_actions_missing,omitempty"`
-	URLSchemes               []string `json:"url_schemes,omitempty"`
-	Warnings                 []string `json:"warnings,omitempty"`
+	AppInstalled             bool               `json:"app_installed"`
+	AppPath                  string             `json:"app_path,omitempty"`
+	BundleID                 string             `json:"bundle_id,omitempty"`
+	Version                  string             `json:"version,omitempty"`
+	ShortcutsCLI             bool               `json:"shortcuts_cli"`
+	ShortcutsCLIPath         string             `json:"shortcuts_cli_path,omitempty"`
+	ShortcutsFolder          string             `json:"shortcuts_folder,omitempty"`
+	ShortcutCount            int                `json:"shortcut_count,omitempty"`
+	ShortcutActionsAvailable []string           `json:"shortcut_actions_available,omitempty"`
+	ShortcutActionsMissing   []string           `json:"shortcut_actions_missing,omitempty"`
+	ShortcutNearMisses       []shortcutNearMiss `json:"shortcut_near_misses,omitempty"`
+	TaskMappings             []taskMappingCheck `json:"task_mappings,omitempty"`
+	URLSchemes               []string           `json:"url_schemes,omitempty"`
+	Warnings                 []string           `json:"warnings,omitempty"`
+}
+
+// taskMappingCheck reports whether a task-scoped mapping (or one of its
+// fallbacks) is in the shortcut library.
+type taskMappingCheck struct {
+	Action    string `json:"action"`
+	Task      string `json:"task"`
+	Shortcut  string `json:"shortcut"`
+	Available bool   `json:"available"`
 }
 
 func newDoctorCmd(opts *rootOptions) *cobra.Command {
@@ -33,7 +45,7 @@ func newDoctorCmd(opts *rootOptions) *cobra.Command {
 		Use:   "doctor",
 		Short: "Verify Streaks installation and Shortcuts availability",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			report, err := runDoctor(context.Background())
+			report, err := runDoctor(context.Background(), opts.folder)
 			if err != nil {
 				return err
 			}
@@ -55,7 +67,8 @@ func newDoctorCmd(opts *rootOptions) *cobra.Command {
 	return cmd
 }
 
-func runDoctor(ctx context.Context) (doctorReport, error) {
+// runDoctor checks the app and shortcut coverage; folder is the --folder flag.
+func runDoctor(ctx context.Context, folder string) (doctorReport, error) {
 	report := doctorReport{}
 
 	if _, err := os.Stat("/usr/bin/shortcuts"); err == nil {
@@ -79,20 +92,23 @@ func runDoctor(ctx context.Context) (doctorReport, error) {
 	}
 
 	if report.ShortcutsCLI {
-		list, err := shortcuts.List(ctx)
+		cfg, _, cfgErr := config.Load()
+		if cfgErr != nil {
+			report.Warnings = append(report.Warnings, cfgErr.Error())
+		}
+		report.ShortcutsFolder = shortcutsFolder(folder, cfg)
+		list, err := shortcuts.ListFolder(ctx, report.ShortcutsFolder)
 		if err != nil {
 			report.Warnings = append(report.Warnings, err.Error())
 			return report, nil
 		}
 		report.ShortcutCount = len(list)
+		report.TaskMappings = checkTaskMappings(cfg, list)
 		if discErr == nil {
-			cfg, _, cfgErr := config.Load()
-			if cfgErr != nil {
-				report.Warnings = append(report.Warnings, cfgErr.Error())
-			}
-			available, missing := shortcutCoverage(discovery.DefaultActionDefinitions(), disc, list, cfg.Mappings)
+			available, missing, near := shortcutCoverage(discovery.DefaultActionDefinitions(), disc, list, cfg.Mappings)
 			report.ShortcutActionsAvailable = available
 			report.ShortcutActionsMissing = missing
+			report.ShortcutNearMisses = near
 		}
 	}
 
@@ -125,12 +141,30 @@ func printDoctor(report doctorReport) {
 	} else {
 		fmt.Println("Shortcuts CLI: MISSING")
 	}
+	if report.ShortcutsFolder != "" {
+		fmt.Printf("Shortcuts folder: %s (%d shortcuts)\n", report.ShortcutsFolder, report.ShortcutCount)
+	}
 	if len(report.ShortcutActionsMissing) == 0 {
 		fmt.Println("Streaks shortcuts: OK")
 	} else {
 		fmt.Printf("Streaks shortcuts: missing %d\n", len(report.ShortcutActionsMissing))
 		for _, action := range report.ShortcutActionsMissing {
 			fmt.Printf("  - %s\n", action)
+			for _, miss := range report.ShortcutNearMisses {
+				if miss.Action == action {
+					fmt.Printf("      near miss: %q (%.2f, expected %q)\n", miss.Name, miss.Score, miss.Candidate)
+				}
+			}
+		}
+	}
+	if len(report.TaskMappings) > 0 {
+		fmt.Println("Task mappings:")
+		for _, check := range report.TaskMappings {
+			state := "OK"
+			if !check.Available {
+				state = "MISSING"
+			}
+			fmt.Printf("  - %s: %s (%s)\n", mappingKeyLabel(check.Action, check.Task), state, check.Shortcut)
 		}
 	}
 	if len(report.Warnings) > 0 {
@@ -140,3 +174,21 @@ func printDoctor(report doctorReport) {
 		}
 	}
 }
+
+func checkTaskMappings(cfg config.Config, list []shortcuts.Shortcut) []taskMappingCheck {
+	entries := linksReport{TaskMappings: cfg.TaskMappings}.entries()
+	checks := make([]taskMappingCheck, 0, len(entries))
+	for _, entry := range entries {
+		var names []string
+		for _, cand := range mappingCandidates(entry.Shortcut, sourceTaskMapping) {
+			names = append(names, cand.Name)
+		}
+		checks = append(checks, taskMappingCheck{
+			Action:    entry.Action,
+			Task:      entry.Task,
+			Shortcut:  mappingLabel(entry.Shortcut),
+			Available: matchShortcutName(list, names) != "",
+		})
+	}
+	return checks
+}
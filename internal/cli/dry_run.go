@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/shortcuts"
+)
+
+// dryRunPlan explains how an action would be resolved and run.
+type dryRunPlan struct {
+	DryRun       bool                          `json:"dry_run"`
+	Action       string                        `json:"action"`
+	Shortcut     string                        `json:"shortcut"`
+	Source       string                        `json:"source,omitempty"`
+	Strategy     string                        `json:"strategy,omitempty"`
+	Match        string                        `json:"match,omitempty"`
+	Score        float64                       `json:"score,omitempty"`
+	Mapping      *config.ShortcutRef           `json:"mapping,omitempty"`
+	Candidates   []discovery.ShortcutCandidate `json:"candidates,omitempty"`
+	Fallbacks    []string                      `json:"fallbacks,omitempty"`
+	NearMisses   []shortcutMatch               `json:"near_misses,omitempty"`
+	ListError    string                        `json:"list_error,omitempty"`
+	Argv         []string                      `json:"argv"`
+	OutputType   string                        `json:"output_type"`
+	Extract      string                        `json:"extract,omitempty"`
+	TimeoutMS    int64                         `json:"timeout_ms"`
+	Retries      int                           `json:"retries"`
+	RetryDelayMS int64                         `json:"retry_delay_ms"`
+	Guards       []string                      `json:"guards,omitempty"`
+	InputAdapter string                        `json:"input_adapter,omitempty"`
+	InputType    string                        `json:"input_type,omitempty"`
+	InputFiles   []string                      `json:"input_files,omitempty"`
+	Save         string                        `json:"save,omitempty"`
+	OutputDir    string                        `json:"output_dir,omitempty"`
+	Overwrite    bool                          `json:"overwrite,omitempty"`
+	Input        any                           `json:"input,omitempty"`
+}
+
+// Placeholders for the temporary paths created by shortcuts.RunWithOptions;
+// the input placeholder takes the input file extension.
+const (
+	dryRunInputPath = "<input%s>"
+	dryRunOutputDir = "<output-dir>"
+)
+
+// buildDryRunPlan mirrors runResolvedShortcut: a matched or mapped shortcut
+// runs as is, otherwise the candidates are tried in order until one exists.
+// Input is shown as the first shortcut would receive it.
+func buildDryRunPlan(actionID string, resolution shortcutResolution, input []byte, cmdOpts *actionCmdOptions, opts *rootOptions) (dryRunPlan, error) {
+	plan := dryRunPlan{
+		DryRun:     true,
+		Action:     actionID,
+		Shortcut:   resolution.first(),
+		Source:     resolution.Source,
+		Strategy:   resolution.Strategy,
+		Match:      resolution.Match,
+		Mapping:    resolution.Mapping,
+		Candidates: resolution.Origins,
+		NearMisses: resolution.NearMisses,
+		Guards:     cmdOpts.guardNames(),
+	}
+	spec := resolution.output(plan.Shortcut)
+	plan.OutputType = shortcutsOutputType(opts, spec.Type)
+	if spec.Extract != nil {
+		plan.Extract = spec.Extract.String()
+	}
+	if resolution.Match == matchFuzzy {
+		plan.Score = resolution.Score
+	}
+	if resolution.Shortcut == "" && len(resolution.Candidates) > 1 {
+		plan.Fallbacks = resolution.Candidates[1:]
+	}
+	if resolution.ListErr != nil {
+		plan.ListError = resolution.ListErr.Error()
+	}
+	run := resolution.runOptions(plan.Shortcut, cmdOpts)
+	run.OutputType = plan.OutputType
+	plan.InputType, plan.InputFiles, plan.Save = run.InputType, run.InputFiles, run.SavePath
+	var inputPaths []string
+	if input != nil || len(run.InputFiles) == 0 {
+		inputPaths = append(inputPaths, fmt.Sprintf(dryRunInputPath, shortcuts.InputExt(run.InputType)))
+	}
+	inputPaths = append(inputPaths, run.InputFiles...)
+	plan.Argv = append([]string{shortcuts.Binary}, shortcuts.RunArgs(plan.Shortcut, inputPaths, dryRunOutputDir, run)...)
+	if opts != nil {
+		plan.OutputDir = opts.outputDir
+		plan.Overwrite = opts.overwrite
+		plan.TimeoutMS = opts.timeout.Milliseconds()
+		plan.Retries = opts.retries
+		plan.RetryDelayMS = opts.retryWait.Milliseconds()
+		if plan.RetryDelayMS <= 0 {
+			plan.RetryDelayMS = time.Second.Milliseconds()
+		}
+		if opts.noOutput {
+			plan.TimeoutMS = 0
+		}
+	}
+	if adapter := resolution.adapter(plan.Shortcut); adapter != nil {
+		plan.InputAdapter = adapter.String()
+		adapted, err := adapter.Apply(input)
+		if err != nil {
+			return plan, exitError(ExitCodeUsage, err)
+		}
+		input = adapted
+	}
+	if input != nil {
+		var parsed any
+		if err := json.Unmarshal(input, &parsed); err == nil {
+			plan.Input = parsed
+		} else {
+			plan.Input = string(input)
+		}
+	}
+	return plan, nil
+}
+
+func printDryRun(opts *rootOptions, cmdOpts *actionCmdOptions, plan dryRunPlan) error {
+	if opts != nil && opts.noOutput {
+		return nil
+	}
+	if opts != nil && opts.isAgent() {
+		return output.PrintJSON(os.Stdout, plan, false)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Dry run:\t%s\n", plan.Action)
+	fmt.Fprintf(w, "Shortcut:\t%s (%s)\n", plan.Shortcut, describeResolution(plan))
+	switch {
+	case plan.Mapping != nil && plan.Source == sourceTaskMapping:
+		fmt.Fprintf(w, "Mapping:\t%s (task mapping)\n", formatMapping(*plan.Mapping))
+	case plan.Mapping != nil:
+		fmt.Fprintf(w, "Mapping:\t%s\n", formatMapping(*plan.Mapping))
+	case plan.Source == sourceDiscovery:
+		fmt.Fprintf(w, "Mapping:\tnone (run st link %s to pin one)\n", plan.Action)
+	}
+	for i, cand := range plan.Candidates {
+		label := ""
+		if i == 0 {
+			label = "Candidates:"
+		}
+		origin := cand.Origin
+		if cand.Template != "" {
+			origin += " " + cand.Template
+		}
+		fmt.Fprintf(w, "%s\t%s\t[%s]\n", label, cand.Name, origin)
+	}
+	if len(plan.Fallbacks) > 0 {
+		fmt.Fprintf(w, "Fallbacks:\t%s\n", strings.Join(plan.Fallbacks, ", "))
+	}
+	for i, miss := range plan.NearMisses {
+		label := ""
+		if i == 0 {
+			label = "Near misses:"
+		}
+		fmt.Fprintf(w, "%s\t%s\t[%.2f for %s]\n", label, miss.Name, miss.Score, miss.Candidate)
+	}
+	if plan.Strategy != "" {
+		fmt.Fprintf(w, "Strategy:\t%s\n", plan.Strategy)
+	}
+	fmt.Fprintf(w, "Command:\t%s\n", formatArgv(plan.Argv))
+	if plan.Input != nil {
+		raw, _ := json.Marshal(plan.Input)
+		if text, ok := plan.Input.(string); ok {
+			raw = []byte(text)
+		}
+		fmt.Fprintf(w, "Input:\t%s\n", raw)
+	}
+	if plan.InputAdapter != "" {
+		fmt.Fprintf(w, "Input adapter:\t%s\n", plan.InputAdapter)
+	}
+	for _, path := range plan.InputFiles {
+		fmt.Fprintf(w, "Input file:\t%s\n", path)
+	}
+	fmt.Fprintf(w, "Output type:\t%s\n", plan.OutputType)
+	if plan.Save != "" {
+		fmt.Fprintf(w, "Save to:\t%s\n", plan.Save)
+	}
+	if plan.Overwrite && (plan.Save != "" || plan.OutputDir != "") {
+		fmt.Fprintf(w, "Overwrite:\texisting files are replaced\n")
+	}
+	if plan.Extract != "" {
+		fmt.Fprintf(w, "Extract:\t%s (raw output kept as result_raw)\n", plan.Extract)
+	}
+	timeout := "none"
+	if plan.TimeoutMS > 0 {
+		timeout = (time.Duration(plan.TimeoutMS) * time.Millisecond).String()
+	}
+	fmt.Fprintf(w, "Timeout:\t%s\n", timeout)
+	if plan.Retries > 0 {
+		fmt.Fprintf(w, "Retries:\t%d (first delay %s, doubling)\n", plan.Retries, time.Duration(plan.RetryDelayMS)*time.Millisecond)
+	} else {
+		fmt.Fprintf(w, "Retries:\tnone\n")
+	}
+	if len(plan.Guards) > 0 {
+		fmt.Fprintf(w, "Guards:\t%s (checked with task-status before running)\n", strings.Join(plan.Guards, ", "))
+	}
+	return w.Flush()
+}
+
+func describeResolution(plan dryRunPlan) string {
+	switch {
+	case plan.Source == sourceFlag:
+		return "from --shortcut"
+	case isMappingSource(plan.Source) && plan.Match == "" && len(plan.Fallbacks) == 0 && len(plan.Candidates) <= 1:
+		if plan.Source == sourceTaskMapping {
+			return "from task mapping"
+		}
+		return "from config mapping"
+	case plan.Match == matchFuzzy:
+		return fmt.Sprintf("fuzzy match in shortcut list, score %.2f", plan.Score)
+	case plan.Match != "":
+		return fmt.Sprintf("%s match in shortcut list, from %s", strings.ReplaceAll(plan.Match, "_", " "), plan.Source)
+	case plan.ListError != "":
+		return "unverified: shortcut list unavailable, candidates are tried in order"
+	default:
+		return "not in shortcut list, candidates are tried in order"
+	}
+}
+
+func formatMapping(ref config.ShortcutRef) string {
+	switch {
+	case ref.Name != "" && ref.ID != "":
+		return fmt.Sprintf("%s (%s)", ref.Name, ref.ID)
+	case ref.Name != "":
+		return ref.Name
+	default:
+		return ref.ID
+	}
+}
+
+func formatArgv(argv []string) string {
+	quoted := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"\\$<>") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -3,15 +3,20 @@ package cli
 import "errors"
 
 const (
+	ExitCodeCheckFailed      = 1
 	ExitCodeUsage            = 2
 	ExitCodeAppMissing       = 10
 	ExitCodeShortcutsMissing = 11
 	ExitCodeShortcutMissing  = 12
 	ExitCodeActionFailed     = 13
+	ExitCodeStatusUnknown    = 14
+	ExitCodeInterrupted      = 130
 )
 
 func errorCodeLabel(code int) string {
 	switch code {
+	case ExitCodeCheckFailed:
+		return "check_failed"
 	case ExitCodeUsage:
 		return "usage"
 	case ExitCodeAppMissing:
@@ -22,14 +27,19 @@ func errorCodeLabel(code int) string {
 		return "shortcut_missing"
 	case ExitCodeActionFailed:
 		return "action_failed"
+	case ExitCodeStatusUnknown:
+		return "status_unknown"
+	case ExitCodeInterrupted:
+		return "interrupted"
 	default:
 		return ""
 	}
 }
 
 type ExitError struct {
-	Code int
-	Err  error
+	Code   int
+	Err    error
+	Silent bool
 }
 
 func (e ExitError) Error() string {
@@ -40,6 +50,17 @@ func exitError(code int, err error) error {
 	return ExitError{Code: code, Err: err}
 }
 
+// silentExitError sets the exit code without printing anything, for commands
+// whose result is conveyed by the exit status alone.
+func silentExitError(code int, err error) error {
+	return ExitError{Code: code, Err: err, Silent: true}
+}
+
+func isSilentExit(err error) bool {
+	var ee ExitError
+	return errors.As(err, &ee) && ee.Silent
+}
+
 func exitCodeFromError(err error) (int, error) {
 	var ee ExitError
 	if errors.As(err, &ee) {
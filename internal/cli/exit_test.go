@@ -4,11 +4,14 @@ import "testing"
 
 func TestErrorCodeLabel(t *testing.T) {
 	cases := map[int]string{
+		ExitCodeCheckFailed:      "check_failed",
 		ExitCodeUsage:            "usage",
 		ExitCodeAppMissing:       "app_missing",
 		ExitCodeShortcutsMissing: "shortcuts_missing",
 		ExitCodeShortcutMissing:  "shortcut_missing",
 		ExitCodeActionFailed:     "action_failed",
+		ExitCodeStatusUnknown:    "status_unknown",
+		ExitCodeInterrupted:      "interrupted",
 		0:                        "",
 		999:                      "",
 	}
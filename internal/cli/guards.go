@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"streaks-cli/internal/output"
+)
+
+const (
+	guardIfPending  = "if-pending"
+	guardUnlessDone = "unless-done"
+)
+
+var guardActions = map[string]bool{
+	"task-complete": true,
+	"task-miss":     true,
+	"task-reminder": true,
+	"timer-start":   true,
+	"timer-stop":    true,
+}
+
+var errMissingGuardTask = errors.New("missing task for --if-pending/--unless-done")
+
+type guardOutcome struct {
+	Guards   []string `json:"guards"`
+	Status   string   `json:"status"`
+	Shortcut string   `json:"shortcut,omitempty"`
+	Pass     bool     `json:"pass"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+func (o *actionCmdOptions) hasGuard() bool {
+	return o != nil && (o.ifPending || o.unlessDone)
+}
+
+func (o *actionCmdOptions) guardNames() []string {
+	if o == nil {
+		return nil
+	}
+	var names []string
+	if o.ifPending {
+		names = append(names, guardIfPending)
+	}
+	if o.unlessDone {
+		names = append(names, guardUnlessDone)
+	}
+	return names
+}
+
+func evaluateGuard(ctx context.Context, session *actionSession, task string, cmdOpts *actionCmdOptions, opts *rootOptions) (guardOutcome, error) {
+	outcome := guardOutcome{Guards: cmdOpts.guardNames(), Status: taskStateUnknown}
+	inv, err := session.invoke(ctx, "task-status", task, "", opts)
+	outcome.Shortcut = inv.Shortcut
+	if err != nil {
+		return outcome, err
+	}
+	outcome.Status = parseTaskStatus(inv.Result.Output).State
+	outcome.Pass, outcome.Reason = guardPasses(outcome.Status, cmdOpts.ifPending, cmdOpts.unlessDone)
+	return outcome, nil
+}
+
+func guardPasses(status string, ifPending, unlessDone bool) (bool, string) {
+	if unlessDone && status == taskStateDone {
+		return false, "task is already done"
+	}
+	if ifPending && status != taskStatePending {
+		if status == taskStateUnknown {
+			return false, "task status could not be determined"
+		}
+		return false, fmt.Sprintf("task is %s, not pending", status)
+	}
+	return true, ""
+}
+
+func skippedActionEnvelope(actionID, shortcutName string, input []byte, guard guardOutcome) actionEnvelope {
+	envelope := actionEnvelope{
+		OK:        true,
+		Skipped:   true,
+		Reason:    guard.Reason,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Action:    actionEnvelopeInfo{ID: actionID},
+		Shortcut:  actionShortcutInfo{Name: shortcutName},
+		Guard:     &guard,
+	}
+	if len(input) > 0 {
+		envelope.Input = normalizeInput(input)
+	}
+	return envelope
+}
+
+func emitSkipped(actionID, shortcutName string, input []byte, guard guardOutcome, opts *rootOptions) error {
+	if opts != nil && opts.noOutput {
+		return nil
+	}
+	if opts != nil && opts.isAgent() {
+		return output.PrintJSON(os.Stdout, skippedActionEnvelope(actionID, shortcutName, input, guard), false)
+	}
+	_, err := fmt.Fprintf(os.Stdout, "Skipped %s: %s\n", actionID, guard.Reason)
+	return err
+}
@@ -18,11 +18,13 @@ import (
 type installResult struct {
 	ShortcutActionsAvailable []string `json:"shortcut_actions_available,omitempty"`
 	ShortcutActionsMissing   []string `json:"shortcut_actions_missing,omitempty"`
+	ShortcutsFolder          string   `json:"shortcuts_folder,omitempty"`
 	Note                     string   `json:"note,omitempty"`
 	ImportDir                string   `json:"import_dir,omitempty"`
 	Imported                 []string `json:"imported,omitempty"`
 	ImportErrors             []string `json:"import_errors,omitempty"`
 	ImportWarning            string   `json:"import_warning,omitempty"`
+	ImportHint               string   `json:"import_hint,omitempty"`
 }
 
 func newInstallCmd(opts *rootOptions) *cobra.Command {
@@ -31,7 +33,7 @@ func newInstallCmd(opts *rootOptions) *cobra.Command {
 		Use:   "install",
 		Short: "Verify Streaks shortcuts are ready to use",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			result, err := runInstall(context.Background(), installOpts)
+			result, err := runInstall(context.Background(), installOpts, opts.folder)
 			if err != nil {
 				return err
 			}
@@ -45,6 +47,9 @@ func newInstallCmd(opts *rootOptions) *cobra.Command {
 				return installExitError(result)
 			}
 			fmt.Println("Streaks shortcut readiness")
+			if result.ShortcutsFolder != "" {
+				fmt.Printf("Shortcuts folder: %s\n", result.ShortcutsFolder)
+			}
 			if len(result.ShortcutActionsMissing) == 0 {
 				fmt.Println("All non-task actions have matching shortcuts.")
 			} else {
@@ -69,6 +74,9 @@ func newInstallCmd(opts *rootOptions) *cobra.Command {
 			if result.ImportWarning != "" {
 				fmt.Printf("Import warning: %s\n", result.ImportWarning)
 			}
+			if result.ImportHint != "" {
+				fmt.Println(result.ImportHint)
+			}
 			if result.Note != "" {
 				fmt.Printf("Note: %s\n", result.Note)
 			}
@@ -85,7 +93,8 @@ type installOptions struct {
 	importDir       string
 }
 
-func runInstall(ctx context.Context, installOpts *installOptions) (installResult, error) {
+// runInstall checks shortcut coverage; folder is the --folder flag.
+func runInstall(ctx context.Context, installOpts *installOptions, folder string) (installResult, error) {
 	note := "The CLI uses existing Streaks shortcuts. Create shortcuts or map them with st link."
 	disc, err := discovery.Discover(ctx)
 	if err != nil {
@@ -94,18 +103,20 @@ func runInstall(ctx context.Context, installOpts *installOptions) (installResult
 	if _, err := os.Stat(disc.ShortcutsCLIPath); err != nil {
 		return installResult{}, exitError(ExitCodeShortcutsMissing, errors.New("shortcuts CLI not available"))
 	}
-	list, err := shortcuts.List(ctx)
-	if err != nil {
-		return installResult{}, exitError(ExitCodeShortcutsMissing, err)
-	}
 	cfg, _, cfgErr := config.Load()
 	if cfgErr != nil {
 		return installResult{}, cfgErr
 	}
-	available, missing := shortcutCoverage(discovery.DefaultActionDefinitions(), disc, list, cfg.Mappings)
+	folder = shortcutsFolder(folder, cfg)
+	list, err := shortcuts.ListFolder(ctx, folder)
+	if err != nil {
+		return installResult{}, exitError(ExitCodeShortcutsMissing, err)
+	}
+	available, missing, _ := shortcutCoverage(discovery.DefaultActionDefinitions(), disc, list, cfg.Mappings)
 	result := installResult{
 		ShortcutActionsAvailable: available,
 		ShortcutActionsMissing:   missing,
+		ShortcutsFolder:          folder,
 		Note:                     note,
 	}
 	if installOpts != nil && installOpts.importShortcuts {
@@ -126,6 +137,9 @@ func runInstall(ctx context.Context, installOpts *installOptions) (installResult
 			if impErr != nil && result.ImportWarning == "" {
 				result.ImportWarning = impErr.Error()
 			}
+			if folder != "" && len(imp.Opened) > 0 {
+				result.ImportHint = fmt.Sprintf("Move the imported shortcuts into the %q folder in the Shortcuts app; st only looks there.", folder)
+			}
 		}
 	}
 	return result, nil
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/journal"
+	"streaks-cli/internal/output"
+)
+
+var journalActions = map[string]bool{
+	"task-complete": true,
+	"task-miss":     true,
+}
+
+type journalOptions struct {
+	task   string
+	text   string
+	since  string
+	until  string
+	limit  int
+	output string
+}
+
+func newJournalCmd(opts *rootOptions) *cobra.Command {
+	journalOpts := &journalOptions{}
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "List notes recorded with task-complete/task-miss --note",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			filter, err := journalFilter(journalOpts, time.Now())
+			if err != nil {
+				return exitError(ExitCodeUsage, err)
+			}
+			format, err := journalOutputFormat(journalOpts.output, opts)
+			if err != nil {
+				return exitError(ExitCodeUsage, err)
+			}
+			entries, err := journal.Load()
+			if err != nil {
+				return err
+			}
+			entries = journal.Select(entries, filter)
+			if journalOpts.limit > 0 && len(entries) > journalOpts.limit {
+				entries = entries[len(entries)-journalOpts.limit:]
+			}
+			if opts.noOutput {
+				return nil
+			}
+			return printJournal(entries, format)
+		},
+	}
+	cmd.Flags().StringVar(&journalOpts.task, "task", "", "Only show entries for this task")
+	cmd.Flags().StringVar(&journalOpts.text, "grep", "", "Only show entries whose note or task contains this text")
+	cmd.Flags().StringVar(&journalOpts.since, "since", "", "Only show entries at or after this time (e.g. 2026-01-31, 7d, 12h, RFC3339)")
+	cmd.Flags().StringVar(&journalOpts.until, "until", "", "Only show entries before this time (dates include the whole day)")
+	cmd.Flags().IntVar(&journalOpts.limit, "limit", 0, "Only show the most recent N entries")
+	cmd.Flags().StringVar(&journalOpts.output, "output", "", "Output format: plain, json, or ndjson (default: plain, ndjson with --agent)")
+	return cmd
+}
+
+func journalFilter(journalOpts *journalOptions, now time.Time) (journal.Filter, error) {
+	filter := journal.Filter{Task: journalOpts.task, Text: journalOpts.text}
+	if journalOpts.since != "" {
+		since, err := parseTimeBound(journalOpts.since, now, false)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = since
+	}
+	if journalOpts.until != "" {
+		until, err := parseTimeBound(journalOpts.until, now, true)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --until: %w", err)
+		}
+		filter.Until = until
+	}
+	return filter, nil
+}
+
+func journalOutputFormat(value string, opts *rootOptions) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		if opts.isAgent() {
+			return "ndjson", nil
+		}
+		return "plain", nil
+	case "plain", "json", "ndjson":
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported --output %q (use plain, json, or ndjson)", value)
+	}
+}
+
+func printJournal(entries []journal.Entry, format string) error {
+	switch format {
+	case "json":
+		return output.PrintJSON(os.Stdout, entries, true)
+	case "ndjson":
+		for _, entry := range entries {
+			if err := output.PrintJSON(os.Stdout, entry, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println("No journal entries")
+		return nil
+	}
+	for _, entry := range entries {
+		stamp := entry.Timestamp
+		if ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+			stamp = ts.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", stamp, entry.Action, entry.Task, entry.Note)
+	}
+	return nil
+}
+
+func recordJournal(actionID, shortcutName string, input []byte, result runResult, count int, cmdOpts *actionCmdOptions) error {
+	if cmdOpts == nil || strings.TrimSpace(cmdOpts.note) == "" || !journalActions[actionID] {
+		return nil
+	}
+	entry := journal.Entry{
+		Action:     actionID,
+		Task:       actionTask(cmdOpts, input),
+		Note:       strings.TrimSpace(cmdOpts.note),
+		Shortcut:   shortcutName,
+		Attempts:   result.Attempts,
+		DurationMS: result.Duration.Milliseconds(),
+	}
+	if count > 1 {
+		entry.Count = count
+	}
+	if len(input) > 0 {
+		entry.Input = normalizeInput(input)
+	}
+	if len(result.Output) > 0 {
+		entry.Result = normalizeShortcutOutput(result.Output, shortcutName)
+	}
+	_, err := journal.Append(entry)
+	return err
+}
@@ -1,32 +1,44 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"streaks-cli/internal/config"
 	"streaks-cli/internal/output"
+	"streaks-cli/internal/shortcuts"
 )
 
 type linkReport struct {
 	Path     string             `json:"path"`
 	Action   string             `json:"action"`
+	Task     string             `json:"task,omitempty"`
 	Shortcut config.ShortcutRef `json:"shortcut"`
 	Note     string             `json:"note,omitempty"`
 }
 
 type linksReport struct {
-	Path     string                        `json:"path"`
-	Mappings map[string]config.ShortcutRef `json:"mappings,omitempty"`
+	Path         string                                   `json:"path"`
+	Mappings     map[string]config.ShortcutRef            `json:"mappings,omitempty"`
+	TaskMappings map[string]map[string]config.ShortcutRef `json:"task_mappings,omitempty"`
 }
 
 func newLinkCmd(opts *rootOptions) *cobra.Command {
 	var shortcut string
 	var shortcutName string
 	var shortcutID string
+	var fallbacks []string
+	var task string
+	var input string
+	var outputType string
+	var extract string
+	var force bool
 	cmd := &cobra.Command{
 		Use:   "link <action-id>",
 		Short: "Map an action to a specific Shortcuts name or identifier",
@@ -49,26 +61,68 @@ func newLinkCmd(opts *rootOptions) *cobra.Command {
 			if ref.Name == "" && ref.ID == "" {
 				return exitError(ExitCodeUsage, fmt.Errorf("provide --shortcut, --shortcut-name, or --shortcut-id"))
 			}
+			if input != "" {
+				adapter, err := config.ParseInputAdapter(input)
+				if err != nil {
+					return exitError(ExitCodeUsage, err)
+				}
+				if adapter.Kind != config.AdapterJSON {
+					ref.Input = &adapter
+				}
+			}
+			ref.Output = strings.TrimSpace(outputType)
+			if extract != "" {
+				extractor, err := config.ParseExtractor(extract)
+				if err != nil {
+					return exitError(ExitCodeUsage, err)
+				}
+				ref.Extract = &extractor
+			}
+			for _, fallback := range fallbacks {
+				if fallback = strings.TrimSpace(fallback); fallback != "" {
+					ref.Fallbacks = append(ref.Fallbacks, config.ShortcutRef{Name: fallback})
+				}
+			}
 			cfg, _, err := config.Load()
 			if err != nil {
 				return err
 			}
-			cfg.Mappings[def.ID] = ref
-			path, err := config.Write(cfg)
+			if !force {
+				if ref, err = verifyLinkRefs(context.Background(), ref, shortcutsFolder(opts.folder, cfg)); err != nil {
+					return err
+				}
+			}
+			task = strings.TrimSpace(task)
+			if task != "" {
+				if !def.RequiresTask {
+					return exitError(ExitCodeUsage, fmt.Errorf("action %s does not take a task", def.ID))
+				}
+				cfg.SetTaskMapping(def.ID, task, ref)
+			} else {
+				cfg.Mappings[def.ID] = ref
+			}
+			path, err := writeConfig(opts, cfg)
 			if err != nil {
 				return err
 			}
-			report := linkReport{Path: path, Action: def.ID, Shortcut: ref}
+			report := linkReport{Path: path, Action: def.ID, Task: task, Shortcut: ref}
 			return printLinkReport(report, opts)
 		},
 	}
 	cmd.Flags().StringVar(&shortcut, "shortcut", "", "Shortcut name or identifier to map to the action")
 	cmd.Flags().StringVar(&shortcutName, "shortcut-name", "", "Shortcut name to map to the action")
 	cmd.Flags().StringVar(&shortcutID, "shortcut-id", "", "Shortcut identifier to map to the action")
+	cmd.Flags().StringArrayVar(&fallbacks, "fallback", nil, "Shortcut name or identifier to try when the mapped one is missing (repeatable, in order)")
+	cmd.Flags().StringVar(&task, "task", "", "Only use this mapping for the given task (takes precedence over the action mapping)")
+	cmd.Flags().StringVar(&input, "input", "", "Input adapter: json (default), text:<Go template> such as 'text:{{.task}}', or rename:old=new,...")
+	cmd.Flags().StringVar(&outputType, "output-type", "", "Output type (UTI) to request from the shortcut, e.g. public.json")
+	cmd.Flags().StringVar(&extract, "extract", "", "Turn raw output into the result: lines, split:<sep>, path:<key.path> or template:<Go template>")
+	cmd.Flags().BoolVar(&force, "force", false, "Link without checking the shortcut library")
 	return cmd
 }
 
 func newUnlinkCmd(opts *rootOptions) *cobra.Command {
+	var task string
 	cmd := &cobra.Command{
 		Use:   "unlink <action-id>",
 		Short: "Remove a shortcut mapping for an action",
@@ -82,27 +136,42 @@ func newUnlinkCmd(opts *rootOptions) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if _, ok := cfg.Mappings[def.ID]; !ok {
+			task = strings.TrimSpace(task)
+			if task != "" {
+				if name, _, ok := cfg.TaskMapping(def.ID, task); ok {
+					task = name
+				}
+			}
+			removed := false
+			if task != "" {
+				removed = cfg.RemoveTaskMapping(def.ID, task)
+			} else if _, ok := cfg.Mappings[def.ID]; ok {
+				delete(cfg.Mappings, def.ID)
+				removed = true
+			}
+			if !removed {
 				report := linkReport{
 					Path:   mustConfigPath(),
 					Action: def.ID,
+					Task:   task,
 					Note:   "no mapping found",
 				}
 				return printLinkReport(report, opts)
 			}
-			delete(cfg.Mappings, def.ID)
-			path, err := config.Write(cfg)
+			path, err := writeConfig(opts, cfg)
 			if err != nil {
 				return err
 			}
 			report := linkReport{
 				Path:   path,
 				Action: def.ID,
+				Task:   task,
 				Note:   "removed",
 			}
 			return printLinkReport(report, opts)
 		},
 	}
+	cmd.Flags().StringVar(&task, "task", "", "Remove the mapping for this task only")
 	return cmd
 }
 
@@ -116,10 +185,11 @@ func newLinksCmd(opts *rootOptions) *cobra.Command {
 				return err
 			}
 			path := mustConfigPath()
-			report := linksReport{Path: path, Mappings: cfg.Mappings}
+			report := linksReport{Path: path, Mappings: cfg.Mappings, TaskMappings: cfg.TaskMappings}
 			return printLinksReport(report, opts)
 		},
 	}
+	cmd.AddCommand(newLinksVerifyCmd(opts))
 	return cmd
 }
 
@@ -130,11 +200,12 @@ func printLinkReport(report linkReport, opts *rootOptions) error {
 	if opts.isAgent() {
 		return output.PrintJSON(os.Stdout, report, false)
 	}
+	label := mappingKeyLabel(report.Action, report.Task)
 	if report.Note != "" {
-		fmt.Printf("%s\t%s\t%s\n", report.Action, report.Note, report.Path)
+		fmt.Printf("%s\t%s\t%s\n", label, report.Note, report.Path)
 		return nil
 	}
-	fmt.Printf("%s\t%s\n", report.Action, shortcutLabel(report.Shortcut))
+	fmt.Printf("%s\t%s\n", label, mappingLabel(report.Shortcut))
 	return nil
 }
 
@@ -142,40 +213,86 @@ func printLinksReport(report linksReport, opts *rootOptions) error {
 	if opts.noOutput {
 		return nil
 	}
+	entries := report.entries()
 	if opts.isAgent() {
-		if len(report.Mappings) == 0 {
-			return nil
-		}
-		ids := make([]string, 0, len(report.Mappings))
-		for id := range report.Mappings {
-			ids = append(ids, id)
-		}
-		sort.Strings(ids)
-		for _, id := range ids {
-			entry := linkReport{
-				Path:     report.Path,
-				Action:   id,
-				Shortcut: report.Mappings[id],
-			}
+		for _, entry := range entries {
 			if err := output.PrintJSON(os.Stdout, entry, false); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	if len(report.Mappings) == 0 {
+	if len(entries) == 0 {
 		fmt.Printf("No mappings configured (%s)\n", report.Path)
 		return nil
 	}
-	ids := make([]string, 0, len(report.Mappings))
-	for id := range report.Mappings {
+	for _, entry := range entries {
+		fmt.Printf("%s\t%s\n", mappingKeyLabel(entry.Action, entry.Task), mappingLabel(entry.Shortcut))
+	}
+	return nil
+}
+
+// entries lists action mappings sorted by action, each followed by its task
+// mappings sorted by task.
+func (r linksReport) entries() []linkReport {
+	ids := make([]string, 0, len(r.Mappings)+len(r.TaskMappings))
+	seen := make(map[string]bool)
+	for id := range r.Mappings {
 		ids = append(ids, id)
+		seen[id] = true
+	}
+	for id := range r.TaskMappings {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
 	}
 	sort.Strings(ids)
+	var entries []linkReport
 	for _, id := range ids {
-		fmt.Printf("%s\t%s\n", id, shortcutLabel(report.Mappings[id]))
+		if ref, ok := r.Mappings[id]; ok {
+			entries = append(entries, linkReport{Path: r.Path, Action: id, Shortcut: ref})
+		}
+		tasks := make([]string, 0, len(r.TaskMappings[id]))
+		for task := range r.TaskMappings[id] {
+			tasks = append(tasks, task)
+		}
+		sort.Strings(tasks)
+		for _, task := range tasks {
+			entries = append(entries, linkReport{Path: r.Path, Action: id, Task: task, Shortcut: r.TaskMappings[id][task]})
+		}
 	}
-	return nil
+	return entries
+}
+
+func mappingKeyLabel(actionID, task string) string {
+	if task == "" {
+		return actionID
+	}
+	return fmt.Sprintf("%s [%s]", actionID, task)
+}
+
+// mappingLabel is the shortcut label followed by its fallbacks.
+func mappingLabel(ref config.ShortcutRef) string {
+	label := refLabel(ref)
+	for _, fallback := range ref.Fallbacks {
+		label += " -> " + refLabel(fallback)
+	}
+	return label
+}
+
+// refLabel is the shortcut label with its input and output settings.
+func refLabel(ref config.ShortcutRef) string {
+	label := shortcutLabel(ref)
+	if ref.Input != nil {
+		label += fmt.Sprintf(" [input %s]", ref.Input)
+	}
+	if ref.Output != "" {
+		label += fmt.Sprintf(" [output %s]", ref.Output)
+	}
+	if ref.Extract != nil {
+		label += fmt.Sprintf(" [extract %s]", ref.Extract)
+	}
+	return label
 }
 
 func shortcutLabel(ref config.ShortcutRef) string {
@@ -188,6 +305,86 @@ func shortcutLabel(ref config.ShortcutRef) string {
 	return ""
 }
 
+// verifyLinkRefs looks up a mapping and its fallbacks in the shortcut library
+// and stores each with the library's name and identifier.
+func verifyLinkRefs(ctx context.Context, ref config.ShortcutRef, folder string) (config.ShortcutRef, error) {
+	list, err := listShortcuts(ctx, folder)
+	if err != nil {
+		return ref, exitError(ExitCodeShortcutsMissing, fmt.Errorf("%w; use --force to link without checking", err))
+	}
+	if ref, err = verifyLinkRef(list, ref, folder); err != nil {
+		return ref, err
+	}
+	for i, fallback := range ref.Fallbacks {
+		if ref.Fallbacks[i], err = verifyLinkRef(list, fallback, folder); err != nil {
+			return ref, err
+		}
+	}
+	return ref, nil
+}
+
+func verifyLinkRef(list []shortcuts.Shortcut, ref config.ShortcutRef, folder string) (config.ShortcutRef, error) {
+	key := ref.ID
+	if key == "" {
+		key = ref.Name
+	}
+	sc, ok := lookupShortcut(list, key)
+	if !ok {
+		msg := fmt.Sprintf("shortcut %q not found in %s", key, libraryName(folder))
+		if suggestions := shortcutSuggestions(list, key); len(suggestions) > 0 {
+			msg += "; did you mean " + strings.Join(suggestions, " or ") + "?"
+		}
+		return ref, exitError(ExitCodeShortcutMissing, errors.New(msg+" (use --force to link anyway)"))
+	}
+	ref.Name, ref.ID = sc.Name, sc.ID
+	return ref, nil
+}
+
+// lookupShortcut finds a shortcut by name or identifier, ignoring case and
+// Unicode differences but not fuzzy matches.
+func lookupShortcut(list []shortcuts.Shortcut, value string) (shortcuts.Shortcut, bool) {
+	match := matchShortcut(list, []string{value}, false)
+	if match.Name == "" {
+		return shortcuts.Shortcut{}, false
+	}
+	byID := match.Kind == matchID || match.Kind == matchIDNormalized
+	for _, sc := range list {
+		if byID && sc.ID == match.Name || !byID && sc.Name == match.Name {
+			return sc, true
+		}
+	}
+	return shortcuts.Shortcut{}, false
+}
+
+func shortcutSuggestions(list []shortcuts.Shortcut, value string) []string {
+	var out []string
+	for _, match := range scoreShortcuts(list, []string{value}) {
+		if match.Score < nearMissThreshold || len(out) == maxNearMisses {
+			break
+		}
+		out = append(out, fmt.Sprintf("%q", match.Name))
+	}
+	return out
+}
+
+// libraryName describes where shortcuts are looked up.
+func libraryName(folder string) string {
+	if folder == "" {
+		return "the shortcut library"
+	}
+	return fmt.Sprintf("Shortcuts folder %q", folder)
+}
+
+// writeConfig saves cfg and drops the cached copy of a shared session, so st
+// shell sees the change on the next line.
+func writeConfig(opts *rootOptions, cfg config.Config) (string, error) {
+	path, err := config.Write(cfg)
+	if opts != nil && opts.session != nil {
+		opts.session.forgetConfig()
+	}
+	return path, err
+}
+
 func mustConfigPath() string {
 	path, err := config.Path()
 	if err != nil {
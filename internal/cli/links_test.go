@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestLinkValidatesShortcut(t *testing.T) {
+	origList := listShortcuts
+	defer func() { listShortcuts = origList }()
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{
+			{Name: "All Tasks", ID: "AAA-111"},
+			{Name: "Task List", ID: "BBB-222"},
+		}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	link := func(args ...string) error {
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"link", "task-list", "--no-output"}, args...))
+		return cmd.Execute()
+	}
+
+	err := link("--shortcut", "All Taks")
+	if code, _ := exitCodeFromError(err); code != ExitCodeShortcutMissing {
+		t.Fatalf("expected shortcut missing, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `did you mean "All Tasks"`) {
+		t.Fatalf("expected a suggestion, got %v", err)
+	}
+
+	if err := link("--shortcut", "all tasks", "--fallback", "bbb-222"); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	cfg, _, _ := config.Load()
+	ref := cfg.Mappings["task-list"]
+	if ref.Name != "All Tasks" || ref.ID != "AAA-111" || len(ref.Fallbacks) != 1 || ref.Fallbacks[0].Name != "Task List" || ref.Fallbacks[0].ID != "BBB-222" {
+		t.Fatalf("expected library names and IDs stored, got %+v", ref)
+	}
+
+	if err := link("--shortcut", "Not Yet Created", "--force"); err != nil {
+		t.Fatalf("link --force: %v", err)
+	}
+	cfg, _, _ = config.Load()
+	if ref := cfg.Mappings["task-list"]; ref.Name != "Not Yet Created" || ref.ID != "" {
+		t.Fatalf("--force should store the name as given, got %+v", ref)
+	}
+}
+
+func TestLinksVerify(t *testing.T) {
+	origList := listShortcuts
+	defer func() { listShortcuts = origList }()
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{
+			{Name: "All Tasks v2", ID: "AAA-111"},
+			{Name: "Complete Task", ID: "CCC-999"},
+			{Name: "Log Run", ID: "DDD-444"},
+		}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	cfg := config.DefaultConfig()
+	cfg.Mappings["task-list"] = config.ShortcutRef{Name: "All Tasks", ID: "AAA-111", Fallbacks: []config.ShortcutRef{{Name: "Gone"}}}
+	cfg.Mappings["task-complete"] = config.ShortcutRef{Name: "Complete Task", ID: "CCC-333"}
+	cfg.SetTaskMapping("task-complete", "Run 5k", config.ShortcutRef{Name: "Log Run"})
+	if _, err := config.Write(cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	def, _ := defaultActionDef("task-list")
+	resolution, err := newActionSession().resolve(context.Background(), def, &actionCmdOptions{}, nil)
+	if err != nil || resolution.Shortcut != "AAA-111" || resolution.Match != matchID {
+		t.Fatalf("renamed mapping should resolve by ID, got %+v (%v)", resolution, err)
+	}
+
+	loaded, _, _ := config.Load()
+	list, _ := listShortcuts(context.Background(), "")
+	checks := verifyLinks(&loaded, list, false)
+	var got []string
+	for _, check := range checks {
+		got = append(got, mappingKeyLabel(check.Action, check.Task)+"="+check.Status)
+	}
+	want := "task-complete=stale_id,task-complete [Run 5k]=incomplete,task-list=renamed,task-list=dangling"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("checks = %v, want %s", got, want)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"links", "verify", "--repair", "--no-output"})
+	err = cmd.Execute()
+	if code, _ := exitCodeFromError(err); code != ExitCodeShortcutMissing {
+		t.Fatalf("dangling fallback should exit %d, got %v", ExitCodeShortcutMissing, err)
+	}
+	repaired, _, _ := config.Load()
+	if ref := repaired.Mappings["task-list"]; ref.Name != "All Tasks v2" || ref.Fallbacks[0].Name != "Gone" {
+		t.Fatalf("renamed mapping should be repaired, got %+v", ref)
+	}
+	if ref := repaired.Mappings["task-complete"]; ref.ID != "CCC-999" {
+		t.Fatalf("stale ID should be repaired, got %+v", ref)
+	}
+	if _, ref, _ := repaired.TaskMapping("task-complete", "Run 5k"); ref.ID != "DDD-444" {
+		t.Fatalf("missing ID should be filled in, got %+v", ref)
+	}
+}
+
+func TestShortcutsFolder(t *testing.T) {
+	origList := listShortcuts
+	defer func() { listShortcuts = origList }()
+	var folders []string
+	listShortcuts = func(_ context.Context, folder string) ([]shortcuts.Shortcut, error) {
+		folders = append(folders, folder)
+		if folder == "Streaks" {
+			return []shortcuts.Shortcut{{Name: "Task List", ID: "AAA-111"}}, nil
+		}
+		return []shortcuts.Shortcut{{Name: "Task List", ID: "ZZZ-999"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	cfg := config.DefaultConfig()
+	cfg.ShortcutsFolder = "Streaks"
+	if _, err := config.Write(cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"link", "task-list", "--shortcut", "Task List", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	cfg, _, _ = config.Load()
+	if ref := cfg.Mappings["task-list"]; ref.ID != "AAA-111" {
+		t.Fatalf("link should look in the configured folder, got %+v", ref)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"--folder", "Other", "link", "task-list", "--shortcut", "Task List", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("link --folder: %v", err)
+	}
+	if _, err := (&rootOptions{}).actionSession().shortcuts(context.Background()); err != nil {
+		t.Fatalf("session list: %v", err)
+	}
+	if strings.Join(folders, ",") != "Streaks,Other,Streaks" {
+		t.Fatalf("unexpected folders: %v", folders)
+	}
+}
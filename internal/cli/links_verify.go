@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/shortcuts"
+)
+
+// Link verification statuses.
+const (
+	linkOK         = "ok"
+	linkRenamed    = "renamed"
+	linkStaleID    = "stale_id"
+	linkIncomplete = "incomplete"
+	linkDangling   = "dangling"
+)
+
+type linkCheck struct {
+	Action string `json:"action"`
+	Task   string `json:"task,omitempty"`
+	// Fallback is the 1-based fallback position, 0 for the mapping itself.
+	Fallback    int    `json:"fallback,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Status      string `json:"status"`
+	CurrentName string `json:"current_name,omitempty"`
+	CurrentID   string `json:"current_id,omitempty"`
+	Repaired    bool   `json:"repaired,omitempty"`
+}
+
+type linksVerifySummary struct {
+	Summary    bool   `json:"summary"`
+	Path       string `json:"path"`
+	Checked    int    `json:"checked"`
+	OK         int    `json:"ok"`
+	Renamed    int    `json:"renamed"`
+	StaleID    int    `json:"stale_id"`
+	Incomplete int    `json:"incomplete"`
+	Dangling   int    `json:"dangling"`
+	Repaired   int    `json:"repaired"`
+}
+
+func newLinksVerifyCmd(opts *rootOptions) *cobra.Command {
+	var repair bool
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check mappings against the shortcut library",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, _, err := config.Load()
+			if err != nil {
+				return err
+			}
+			folder := shortcutsFolder(opts.folder, cfg)
+			list, err := listShortcuts(context.Background(), folder)
+			if err != nil {
+				return exitError(ExitCodeShortcutsMissing, err)
+			}
+			checks := verifyLinks(&cfg, list, repair)
+			summary := summarizeLinkChecks(checks)
+			summary.Path = mustConfigPath()
+			if summary.Repaired > 0 {
+				if summary.Path, err = writeConfig(opts, cfg); err != nil {
+					return err
+				}
+			}
+			if err := printLinksVerify(checks, summary, opts); err != nil {
+				return err
+			}
+			if summary.Dangling > 0 {
+				return exitError(ExitCodeShortcutMissing, fmt.Errorf("%d mapped shortcuts not found in %s", summary.Dangling, libraryName(folder)))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&repair, "repair", false, "Update renamed or re-created shortcuts and fill in missing names or IDs")
+	return cmd
+}
+
+// verifyLinks checks every mapping and fallback; with repair, refs whose
+// shortcut was found under another name or ID are updated in cfg.
+func verifyLinks(cfg *config.Config, list []shortcuts.Shortcut, repair bool) []linkCheck {
+	var checks []linkCheck
+	verifyRef := func(action, task string, fallback int, ref *config.ShortcutRef) {
+		status, sc := checkLinkRef(list, *ref)
+		row := linkCheck{Action: action, Task: task, Fallback: fallback, Name: ref.Name, ID: ref.ID, Status: status}
+		if status != linkOK && status != linkDangling {
+			row.CurrentName, row.CurrentID = sc.Name, sc.ID
+			if repair {
+				ref.Name, ref.ID = sc.Name, sc.ID
+				row.Repaired = true
+			}
+		}
+		checks = append(checks, row)
+	}
+	report := linksReport{Mappings: cfg.Mappings, TaskMappings: cfg.TaskMappings}
+	for _, entry := range report.entries() {
+		ref := entry.Shortcut
+		ref.Fallbacks = append([]config.ShortcutRef(nil), ref.Fallbacks...)
+		verifyRef(entry.Action, entry.Task, 0, &ref)
+		for i := range ref.Fallbacks {
+			verifyRef(entry.Action, entry.Task, i+1, &ref.Fallbacks[i])
+		}
+		if !repair {
+			continue
+		}
+		if entry.Task == "" {
+			cfg.Mappings[entry.Action] = ref
+		} else {
+			cfg.TaskMappings[entry.Action][entry.Task] = ref
+		}
+	}
+	return checks
+}
+
+// checkLinkRef finds a ref by ID first, then by name.
+func checkLinkRef(list []shortcuts.Shortcut, ref config.ShortcutRef) (string, shortcuts.Shortcut) {
+	if ref.ID != "" {
+		for _, sc := range list {
+			if strings.EqualFold(sc.ID, ref.ID) {
+				switch {
+				case ref.Name == "":
+					return linkIncomplete, sc
+				case sc.Name != ref.Name:
+					return linkRenamed, sc
+				}
+				return linkOK, sc
+			}
+		}
+	}
+	if ref.Name != "" {
+		if sc, ok := lookupShortcut(list, ref.Name); ok {
+			switch {
+			case ref.ID != "":
+				return linkStaleID, sc
+			case sc.ID != "":
+				return linkIncomplete, sc
+			}
+			return linkOK, sc
+		}
+	}
+	return linkDangling, shortcuts.Shortcut{}
+}
+
+func summarizeLinkChecks(checks []linkCheck) linksVerifySummary {
+	summary := linksVerifySummary{Summary: true, Checked: len(checks)}
+	for _, check := range checks {
+		switch check.Status {
+		case linkOK:
+			summary.OK++
+		case linkRenamed:
+			summary.Renamed++
+		case linkStaleID:
+			summary.StaleID++
+		case linkIncomplete:
+			summary.Incomplete++
+		case linkDangling:
+			summary.Dangling++
+		}
+		if check.Repaired {
+			summary.Repaired++
+		}
+	}
+	return summary
+}
+
+func printLinksVerify(checks []linkCheck, summary linksVerifySummary, opts *rootOptions) error {
+	if opts.noOutput {
+		return nil
+	}
+	if opts.isAgent() {
+		for _, check := range checks {
+			if err := output.PrintJSON(os.Stdout, check, false); err != nil {
+				return err
+			}
+		}
+		return output.PrintJSON(os.Stdout, summary, false)
+	}
+	if len(checks) == 0 {
+		fmt.Printf("No mappings configured (%s)\n", summary.Path)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MAPPING\tSHORTCUT\tSTATUS\tNOTE")
+	for _, check := range checks {
+		label := mappingKeyLabel(check.Action, check.Task)
+		if check.Fallback > 0 {
+			label += fmt.Sprintf(" fallback %d", check.Fallback)
+		}
+		name := check.Name
+		if name == "" {
+			name = check.ID
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, name, check.Status, linkCheckNote(check))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	pending := summary.Renamed + summary.StaleID + summary.Incomplete - summary.Repaired
+	fmt.Printf("\n%d checked: %d ok, %d renamed, %d stale ID, %d incomplete, %d dangling\n",
+		summary.Checked, summary.OK, summary.Renamed, summary.StaleID, summary.Incomplete, summary.Dangling)
+	switch {
+	case summary.Repaired > 0:
+		fmt.Printf("Repaired %d mappings in %s\n", summary.Repaired, summary.Path)
+	case pending > 0:
+		fmt.Println("Run st links verify --repair to update them.")
+	}
+	return nil
+}
+
+func linkCheckNote(check linkCheck) string {
+	var note string
+	switch check.Status {
+	case linkRenamed:
+		note = fmt.Sprintf("now %q", check.CurrentName)
+	case linkStaleID:
+		note = fmt.Sprintf("ID not found; name now has ID %s", check.CurrentID)
+	case linkIncomplete:
+		note = fmt.Sprintf("found as %s", formatMapping(config.ShortcutRef{Name: check.CurrentName, ID: check.CurrentID}))
+	case linkDangling:
+		note = "not in the shortcut library"
+	}
+	if check.Repaired {
+		note += " (repaired)"
+	}
+	return note
+}
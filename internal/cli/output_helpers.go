@@ -6,3 +6,21 @@ func (o *rootOptions) isAgent() bool {
 	}
 	return o.agent
 }
+
+func (o *rootOptions) actionSession() *actionSession {
+	if o != nil && o.session != nil {
+		// Shell lines share the session but may pass their own --folder.
+		o.session.folder = o.folder
+		return o.session
+	}
+	session := newActionSession()
+	if o != nil {
+		session.folder = o.folder
+	}
+	return session
+}
+
+// allowFuzzy reports whether --fuzzy lets a close shortcut name run.
+func (o *rootOptions) allowFuzzy() bool {
+	return o != nil && o.fuzzy
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/output"
+)
+
+type resolveCandidate struct {
+	Rank     int    `json:"rank"`
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Template string `json:"template,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	// Exists is nil when the shortcut list could not be read.
+	Exists  *bool   `json:"exists"`
+	Library string  `json:"library,omitempty"`
+	Match   string  `json:"match,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+	Winner  bool    `json:"winner,omitempty"`
+
+	// group is the resolution source the candidate belongs to.
+	group string
+}
+
+type resolveSummary struct {
+	Summary   bool   `json:"summary"`
+	Action    string `json:"action"`
+	Task      string `json:"task,omitempty"`
+	Shortcut  string `json:"shortcut"`
+	Source    string `json:"source"`
+	Strategy  string `json:"strategy"`
+	Match     string `json:"match,omitempty"`
+	Reason    string `json:"reason"`
+	ListError string `json:"list_error,omitempty"`
+}
+
+type resolveReport struct {
+	Candidates []resolveCandidate
+	Summary    resolveSummary
+}
+
+func newResolveCmd(opts *rootOptions) *cobra.Command {
+	var task string
+	cmd := &cobra.Command{
+		Use:   "resolve <action-id>",
+		Short: "Explain which shortcut an action would run and why",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			def, err := findActionDef(args[0])
+			if err != nil {
+				return exitError(ExitCodeUsage, err)
+			}
+			if def.Transport != discovery.TransportShortcuts {
+				return exitError(ExitCodeUsage, fmt.Errorf("action %s does not run through Shortcuts", def.ID))
+			}
+			report, err := resolveAction(context.Background(), opts.actionSession(), def, strings.TrimSpace(task), opts)
+			if err != nil {
+				return err
+			}
+			return printResolveReport(report, def, opts)
+		},
+	}
+	cmd.Flags().StringVar(&task, "task", "", "Task name used to expand ${task} templates")
+	return cmd
+}
+
+// resolveAction lists every candidate in priority order and marks the one
+// actionSession.resolve picks.
+func resolveAction(ctx context.Context, session *actionSession, def discovery.ActionDef, task string, opts *rootOptions) (resolveReport, error) {
+	report := resolveReport{Summary: resolveSummary{Summary: true, Action: def.ID, Task: task}}
+	resolution, resolveErr := session.resolve(ctx, def, &actionCmdOptions{task: task}, opts)
+
+	library, listErr := session.shortcuts(ctx)
+	if listErr != nil {
+		report.Summary.ListError = listErr.Error()
+	}
+	add := func(cand discovery.ShortcutCandidate, group string) {
+		row := resolveCandidate{
+			group:    group,
+			Rank:     len(report.Candidates) + 1,
+			Name:     cand.Name,
+			Source:   cand.Origin,
+			Template: cand.Template,
+			Key:      cand.Key,
+			Locale:   cand.Locale,
+		}
+		if listErr == nil {
+			match := matchShortcut(library, []string{cand.Name}, opts.allowFuzzy())
+			exists := match.Name != ""
+			row.Exists = &exists
+			row.Library = match.Name
+			row.Match = match.Kind
+			if match.Kind == matchFuzzy {
+				row.Score = match.Score
+			}
+		}
+		report.Candidates = append(report.Candidates, row)
+	}
+
+	cfg, err := session.config()
+	if err != nil {
+		return report, err
+	}
+	strategy, err := effectiveStrategy(cfg, opts)
+	if err != nil {
+		return report, exitError(ExitCodeUsage, err)
+	}
+	report.Summary.Strategy = strategy
+	if _, ref, ok := cfg.TaskMapping(def.ID, task); ok {
+		for _, cand := range mappingCandidates(ref, sourceTaskMapping) {
+			add(cand, sourceTaskMapping)
+		}
+	}
+	for _, cand := range mappingCandidates(cfg.Mappings[def.ID], sourceMapping) {
+		add(cand, sourceMapping)
+	}
+	if disc, err := session.discovery(ctx); err == nil && strategy != config.StrategyMappingOnly {
+		for _, cand := range orderCandidates(actionCandidateDetails(def, disc, task), strategy) {
+			add(cand, sourceDiscovery)
+		}
+	}
+	if resolveErr != nil {
+		return report, resolveErr
+	}
+
+	summary := &report.Summary
+	summary.Shortcut = resolution.first()
+	summary.Source = resolution.Source
+	summary.Match = resolution.Match
+	switch {
+	case isMappingSource(resolution.Source) && resolution.Match == "" && len(resolution.Candidates) == 0:
+		summary.Reason = "config mapping takes priority over discovery"
+		if resolution.Source == sourceTaskMapping {
+			summary.Reason = "task mapping takes priority over action mappings and discovery"
+		}
+		if first := report.Candidates[0]; first.Exists != nil && !*first.Exists {
+			summary.Reason += " (warning: not found in the shortcut library)"
+		}
+	case resolution.Match != "":
+		summary.Reason = fmt.Sprintf("first %s candidate found in the shortcut library (%s match)", resolution.Source, strings.ReplaceAll(resolution.Match, "_", " "))
+	case listErr != nil:
+		summary.Reason = "shortcut list unavailable; candidates are tried in order at run time"
+	default:
+		summary.Reason = "no candidate is in the shortcut library; candidates are tried in order at run time"
+	}
+	for i := range report.Candidates {
+		row := &report.Candidates[i]
+		if row.group != resolution.Source {
+			continue
+		}
+		if row.Name == summary.Shortcut || row.Library == summary.Shortcut {
+			row.Winner = true
+			break
+		}
+	}
+	return report, nil
+}
+
+func printResolveReport(report resolveReport, def discovery.ActionDef, opts *rootOptions) error {
+	if opts.noOutput {
+		return nil
+	}
+	if opts.isAgent() {
+		for _, row := range report.Candidates {
+			if err := output.PrintJSON(os.Stdout, row, false); err != nil {
+				return err
+			}
+		}
+		return output.PrintJSON(os.Stdout, report.Summary, false)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\t#\tCANDIDATE\tSOURCE\tIN LIBRARY")
+	for _, row := range report.Candidates {
+		marker := ""
+		if row.Winner {
+			marker = "→"
+		}
+		source := row.Source
+		if row.Key != "" {
+			source += " " + row.Key
+		}
+		if row.Locale != "" {
+			source += " [" + row.Locale + "]"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", marker, row.Rank, row.Name, source, libraryLabel(row))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nWinner: %s (%s)\n", report.Summary.Shortcut, report.Summary.Reason)
+	fmt.Printf("Strategy: %s\n", report.Summary.Strategy)
+	if report.Summary.ListError != "" {
+		fmt.Printf("Shortcut list: %s\n", report.Summary.ListError)
+	}
+	if def.RequiresTask && report.Summary.Task == "" {
+		fmt.Println("Templates with ${task} were skipped; pass --task to include them.")
+	}
+	return nil
+}
+
+func libraryLabel(row resolveCandidate) string {
+	switch {
+	case row.Exists == nil:
+		return "unknown"
+	case !*row.Exists:
+		return "no"
+	case row.Match == matchExact:
+		return "yes"
+	case row.Match == matchFuzzy:
+		return fmt.Sprintf("yes (fuzzy %.2f: %s)", row.Score, row.Library)
+	default:
+		return fmt.Sprintf("yes (%s: %s)", strings.ReplaceAll(row.Match, "_", " "), row.Library)
+	}
+}
@@ -25,6 +25,18 @@ type rootOptions struct {
 	retryWait       time.Duration
 	configPath      string
 	shortcutsOutput string
+	strategy        string
+	folder          string
+	outputDir       string
+	overwrite       bool
+	fuzzy           bool
+
+	// session, when set, is shared by every command run with these options
+	// (st shell keeps one alive across lines).
+	session *actionSession
+	// stdinReserved means stdin belongs to an outer loop and commands must
+	// not read from it.
+	stdinReserved bool
 }
 
 const envDisableDiscovery = "STREAKS_CLI_DISABLE_DISCOVERY"
@@ -32,7 +44,10 @@ const envAgentMode = "STREAKS_CLI_AGENT"
 const envShortcutsOutput = "STREAKS_CLI_SHORTCUTS_OUTPUT"
 
 func newRootCmd() *cobra.Command {
-	opts := &rootOptions{}
+	return buildRootCmd(&rootOptions{}, availableActionDefs())
+}
+
+func buildRootCmd(opts *rootOptions, defs []discovery.ActionDef) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "st",
 		Short:         "CLI for Streaks (Crunchy Bagel)",
@@ -50,6 +65,11 @@ func newRootCmd() *cobra.Command {
 				}
 			}
 			opts.agent = opts.agent || isTruthy(os.Getenv(envAgentMode))
+			if opts.strategy != "" {
+				if _, err := config.ParseStrategy(opts.strategy); err != nil {
+					return exitError(ExitCodeUsage, err)
+				}
+			}
 			return nil
 		},
 	}
@@ -61,7 +81,12 @@ func newRootCmd() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", 30*time.Second, "Timeout for Shortcuts runs")
 	cmd.PersistentFlags().IntVar(&opts.retries, "retries", 0, "Retry failed Shortcuts runs")
 	cmd.PersistentFlags().DurationVar(&opts.retryWait, "retry-delay", time.Second, "Initial delay between retries")
-	cmd.PersistentFlags().StringVar(&opts.shortcutsOutput, "shortcuts-output", "public.plain-text", "Shortcuts output type (UTI), e.g. public.plain-text or public.json")
+	cmd.PersistentFlags().StringVar(&opts.shortcutsOutput, "shortcuts-output", "", "Shortcuts output type (UTI), e.g. public.plain-text or public.json; overrides mapping and action defaults (default public.plain-text)")
+	cmd.PersistentFlags().StringVar(&opts.strategy, "strategy", "", "Shortcut resolution strategy: auto, wrappers-first, intents-first or mapping-only (default: config prefer, else auto)")
+	cmd.PersistentFlags().StringVar(&opts.folder, "folder", "", "Only consider shortcuts in this Shortcuts folder (default: config shortcuts_folder, else the whole library)")
+	cmd.PersistentFlags().BoolVar(&opts.fuzzy, "fuzzy", false, "Run the closest shortcut (score 0.85 or more) when no candidate matches exactly; otherwise close names are only suggested")
+	cmd.PersistentFlags().StringVar(&opts.outputDir, "output-dir", "", "Directory for binary or large Shortcuts output files (default: a streaks-cli folder in the user cache dir)")
+	cmd.PersistentFlags().BoolVar(&opts.overwrite, "overwrite", false, "Let saved output files (--save, --output-dir) replace existing files instead of adding a numeric suffix")
 	cmd.PersistentFlags().StringVar(&opts.configPath, "config", "", "Path to config file (default: ~/.config/streaks-cli/config.json)")
 
 	cmd.AddCommand(newDiscoverCmd(opts))
@@ -73,8 +98,20 @@ func newRootCmd() *cobra.Command {
 	cmd.AddCommand(newHelpCmd(cmd, opts))
 	cmd.AddCommand(newOpenCmd(opts))
 	cmd.AddCommand(newActionsCmd(opts))
+	cmd.AddCommand(newJournalCmd(opts))
+	cmd.AddCommand(newTodayCmd(opts))
+	cmd.AddCommand(newBatchCmd(opts))
+	cmd.AddCommand(newRunCmd(opts))
+	cmd.AddCommand(newRoutineCmd(opts))
+	cmd.AddCommand(newScriptCmd(opts))
+	cmd.AddCommand(newDoCmd(opts))
+	cmd.AddCommand(newUICmd(opts))
+	cmd.AddCommand(newShellCmd(opts))
+	cmd.AddCommand(newTimerCmd(opts))
+	cmd.AddCommand(newResolveCmd(opts))
+	cmd.AddCommand(newShortcutsCmd(opts))
 
-	addActionCommands(cmd, availableActionDefs(), opts)
+	addActionCommands(cmd, defs, opts)
 
 	return cmd
 }
@@ -82,7 +119,9 @@ func newRootCmd() *cobra.Command {
 func Execute() {
 	if err := newRootCmd().Execute(); err != nil {
 		if code, inner := exitCodeFromError(err); code != 0 {
-			printError(inner.Error(), code)
+			if !isSilentExit(err) {
+				printError(inner.Error(), code)
+			}
 			os.Exit(code)
 		}
 		printError(err.Error(), 1)
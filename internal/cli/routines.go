@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/output"
+)
+
+type routineInfo struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Steps       int                  `json:"steps"`
+	StepList    []config.RoutineStep `json:"step_list,omitempty"`
+}
+
+func newRunCmd(opts *rootOptions) *cobra.Command {
+	var dryRun bool
+	var trace string
+	cmd := &cobra.Command{
+		Use:   "run <routine>",
+		Short: "Run a routine defined in config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			routine, err := loadRoutine(args[0])
+			if err != nil {
+				return err
+			}
+			summary, err := runRoutine(context.Background(), opts.actionSession(), args[0], routine, dryRun, trace, opts)
+			if err != nil {
+				return err
+			}
+			if summary.Stopped {
+				return exitError(ExitCodeActionFailed, fmt.Errorf("routine %s stopped after a failing step", args[0]))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the resolved shortcut for every step without running")
+	cmd.Flags().StringVar(&trace, "trace", "", "Append JSON trace of input/output to a file")
+	return cmd
+}
+
+func newRoutineCmd(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routine",
+		Short: "Inspect routines defined in config",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List routines",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, _, err := config.Load()
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(cfg.Routines))
+			for name := range cfg.Routines {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if opts.noOutput {
+				return nil
+			}
+			if !opts.isAgent() && len(names) == 0 {
+				fmt.Printf("No routines configured (%s)\n", mustConfigPath())
+				return nil
+			}
+			for _, name := range names {
+				routine := cfg.Routines[name]
+				info := routineInfo{Name: name, Description: routine.Description, Steps: len(routine.Steps)}
+				if opts.isAgent() {
+					if err := output.PrintJSON(os.Stdout, info, false); err != nil {
+						return err
+					}
+					continue
+				}
+				fmt.Printf("%s\t%d steps\t%s\n", info.Name, info.Steps, info.Description)
+			}
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "describe <routine>",
+		Short: "Show the steps of a routine",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			routine, err := loadRoutine(args[0])
+			if err != nil {
+				return err
+			}
+			if opts.noOutput {
+				return nil
+			}
+			info := routineInfo{Name: args[0], Description: routine.Description, Steps: len(routine.Steps), StepList: routine.Steps}
+			if opts.isAgent() {
+				return output.PrintJSON(os.Stdout, info, false)
+			}
+			fmt.Printf("Routine: %s\n", info.Name)
+			if info.Description != "" {
+				fmt.Printf("Description: %s\n", info.Description)
+			}
+			for i, step := range routine.Steps {
+				fmt.Printf("%d. %s\n", i+1, describeRoutineStep(step))
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+func loadRoutine(name string) (config.Routine, error) {
+	cfg, _, err := config.Load()
+	if err != nil {
+		return config.Routine{}, err
+	}
+	routine, ok := cfg.Routines[name]
+	if !ok {
+		return config.Routine{}, exitError(ExitCodeUsage, fmt.Errorf("unknown routine: %s", name))
+	}
+	if len(routine.Steps) == 0 {
+		return config.Routine{}, exitError(ExitCodeUsage, fmt.Errorf("routine %s has no steps", name))
+	}
+	return routine, nil
+}
+
+func runRoutine(ctx context.Context, session *actionSession, name string, routine config.Routine, dryRun bool, trace string, opts *rootOptions) (actionSummary, error) {
+	summary := actionSummary{Summary: true, Routine: name, Total: len(routine.Steps)}
+	for i, step := range routine.Steps {
+		if summary.Stopped {
+			summary.NotRun++
+			continue
+		}
+		req := routineStepRequest(name, i, step)
+		req.DryRun = dryRun
+		req.Trace = trace
+		envelope, err := session.execute(ctx, req, opts)
+		switch {
+		case err != nil:
+			summary.Failed++
+			summary.Stopped = !step.ContinueOnError
+		case envelope.Skipped:
+			summary.Skipped++
+		default:
+			summary.OK++
+		}
+		if err := printRoutineStep(i, step, envelope, opts); err != nil {
+			return summary, err
+		}
+	}
+	if opts.noOutput {
+		return summary, nil
+	}
+	if opts.isAgent() {
+		return summary, output.PrintJSON(os.Stdout, summary, false)
+	}
+	if !dryRun {
+		fmt.Printf("%s: %d ok, %d skipped, %d failed, %d not run\n", name, summary.OK, summary.Skipped, summary.Failed, summary.NotRun)
+	}
+	return summary, nil
+}
+
+func routineStepRequest(routine string, index int, step config.RoutineStep) actionRequest {
+	return actionRequest{
+		ID:         fmt.Sprintf("%s#%d", routine, index+1),
+		Action:     step.Action,
+		Task:       step.Task,
+		Status:     step.Status,
+		Input:      step.Input,
+		Shortcut:   step.Shortcut,
+		Note:       step.Note,
+		IfPending:  step.IfPending,
+		UnlessDone: step.UnlessDone,
+	}
+}
+
+func printRoutineStep(index int, step config.RoutineStep, envelope actionEnvelope, opts *rootOptions) error {
+	if opts.noOutput {
+		return nil
+	}
+	if opts.isAgent() {
+		return output.PrintJSON(os.Stdout, envelope, false)
+	}
+	label := describeRoutineStep(step)
+	switch {
+	case envelope.DryRun:
+		fmt.Printf("%d. %s -> %s\n", index+1, label, envelope.Shortcut.Name)
+	case envelope.Skipped:
+		fmt.Printf("%d. %s: skipped (%s)\n", index+1, label, envelope.Reason)
+	case !envelope.OK:
+		fmt.Printf("%d. %s: failed: %s\n", index+1, label, envelope.Error)
+	default:
+		fmt.Printf("%d. %s: ok (%s)\n", index+1, label, envelope.Shortcut.Name)
+	}
+	return nil
+}
+
+func describeRoutineStep(step config.RoutineStep) string {
+	parts := []string{step.Action}
+	if step.Task != "" {
+		parts = append(parts, fmt.Sprintf("%q", step.Task))
+	}
+	if step.Status != "" {
+		parts = append(parts, "status="+step.Status)
+	}
+	if step.Shortcut != "" {
+		parts = append(parts, "shortcut="+step.Shortcut)
+	}
+	if step.IfPending {
+		parts = append(parts, "[if pending]")
+	}
+	if step.UnlessDone {
+		parts = append(parts, "[unless done]")
+	}
+	if step.ContinueOnError {
+		parts = append(parts, "[continue on error]")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestRunRoutineContinueOnError(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+
+	var ran []string
+	runShortcut = func(_ context.Context, _ string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, string(input))
+		if string(input) == `{"task":"Meditate"}` {
+			return nil, errors.New("boom")
+		}
+		return []byte("ok"), nil
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.EnvConfigPath, path)
+	if _, err := config.Write(config.Config{Routines: map[string]config.Routine{
+		"morning": {Steps: []config.RoutineStep{
+			{Action: "task-complete", Task: "Meditate", Shortcut: "Complete Task", ContinueOnError: true},
+			{Action: "task-complete", Task: "Stretch", Shortcut: "Complete Task"},
+			{Action: "timer-start", Task: "Read", Shortcut: "Start Task Timer"},
+		}},
+	}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	routine, err := loadRoutine("morning")
+	if err != nil {
+		t.Fatalf("loadRoutine: %v", err)
+	}
+	summary, err := runRoutine(context.Background(), newActionSession(), "morning", routine, false, "", &rootOptions{noOutput: true})
+	if err != nil {
+		t.Fatalf("runRoutine: %v", err)
+	}
+	if len(ran) != 3 || summary.OK != 2 || summary.Failed != 1 || summary.Stopped {
+		t.Fatalf("unexpected run: ran=%v summary=%+v", ran, summary)
+	}
+
+	ran = nil
+	summary, err = runRoutine(context.Background(), newActionSession(), "morning", routine, true, "", &rootOptions{noOutput: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(ran) != 0 || summary.OK != 3 {
+		t.Fatalf("dry run should not execute shortcuts: ran=%v summary=%+v", ran, summary)
+	}
+
+	if _, err := loadRoutine("evening"); err == nil {
+		t.Fatalf("expected unknown routine error")
+	}
+}
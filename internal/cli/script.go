@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/script"
+)
+
+type scriptOptions struct {
+	dryRun   bool
+	trace    string
+	maxSteps uint64
+}
+
+func newScriptCmd(opts *rootOptions) *cobra.Command {
+	scriptOpts := &scriptOptions{}
+	cmd := &cobra.Command{
+		Use:   "script <file.star>",
+		Short: "Run a Starlark automation script against Streaks actions",
+		Long: "Run a Starlark automation script against Streaks actions.\n\n" +
+			"Built-ins: tasks(), status(task), complete(task, note=), miss(task, note=), remind(task),\n" +
+			"start_timer(task), stop_timer(task), run(action, task=, status=, note=), now() and the time module.\n" +
+			"Scripts cannot load modules or access the filesystem or network. --dry-run is a flag of this\n" +
+			"command; the global --agent and timeout/retry flags apply to every action the script runs.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			src, err := os.ReadFile(args[0])
+			if err != nil {
+				return exitError(ExitCodeUsage, err)
+			}
+			host := newScriptHost(opts.actionSession(), args[0], scriptOpts, opts)
+			err = runScript(context.Background(), args[0], src, host, scriptOpts, opts)
+			if err != nil {
+				return err
+			}
+			if host.summary.Failed > 0 {
+				return exitError(ExitCodeActionFailed, fmt.Errorf("%d script actions failed", host.summary.Failed))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&scriptOpts.dryRun, "dry-run", false, "Resolve actions without running them (status and task lookups still run)")
+	cmd.Flags().StringVar(&scriptOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
+	cmd.Flags().Uint64Var(&scriptOpts.maxSteps, "max-steps", script.DefaultMaxSteps, "Abort scripts after this many Starlark execution steps")
+	return cmd
+}
+
+func runScript(ctx context.Context, filename string, src []byte, host *scriptHost, scriptOpts *scriptOptions, opts *rootOptions) error {
+	err := script.Exec(ctx, filename, src, host, script.Options{
+		MaxSteps: scriptOpts.maxSteps,
+		Print:    host.print,
+	})
+	if err != nil {
+		var runtimeErr *script.RuntimeError
+		if errors.As(err, &runtimeErr) {
+			return exitError(ExitCodeActionFailed, err)
+		}
+		return exitError(ExitCodeUsage, err)
+	}
+	if opts.isAgent() && !opts.noOutput {
+		return output.PrintJSON(os.Stdout, host.summary, false)
+	}
+	return nil
+}
+
+type scriptHost struct {
+	session    *actionSession
+	opts       *rootOptions
+	scriptOpts *scriptOptions
+	summary    actionSummary
+	now        func() time.Time
+}
+
+func newScriptHost(session *actionSession, filename string, scriptOpts *scriptOptions, opts *rootOptions) *scriptHost {
+	return &scriptHost{
+		session:    session,
+		opts:       opts,
+		scriptOpts: scriptOpts,
+		summary:    actionSummary{Summary: true, Script: filename},
+		now:        time.Now,
+	}
+}
+
+func (h *scriptHost) Tasks(ctx context.Context) ([]string, error) {
+	inv, err := h.session.invoke(ctx, "task-list", "", "", h.opts)
+	if err != nil {
+		return nil, err
+	}
+	return parseTaskList(inv.Result.Output), nil
+}
+
+func (h *scriptHost) Status(ctx context.Context, task string) (map[string]any, error) {
+	row := todayStatusRow(ctx, h.session, task, h.opts)
+	return jsonMap(row)
+}
+
+func (h *scriptHost) Run(ctx context.Context, call script.Call) (map[string]any, error) {
+	h.summary.Total++
+	req := actionRequest{
+		ID:     h.summary.Total,
+		Action: call.Action,
+		Task:   call.Task,
+		Status: call.Status,
+		Note:   call.Note,
+		DryRun: h.scriptOpts.dryRun,
+		Trace:  h.scriptOpts.trace,
+	}
+	envelope, err := h.session.execute(ctx, req, h.opts)
+	switch {
+	case err != nil:
+		h.summary.Failed++
+	case envelope.Skipped:
+		h.summary.Skipped++
+	default:
+		h.summary.OK++
+	}
+	if err := h.emit(envelope); err != nil {
+		return nil, err
+	}
+	return jsonMap(envelope)
+}
+
+func (h *scriptHost) Now() time.Time {
+	return h.now()
+}
+
+func (h *scriptHost) print(msg string) {
+	if h.opts.noOutput {
+		return
+	}
+	if h.opts.isAgent() {
+		_ = output.PrintJSON(os.Stdout, map[string]any{"print": msg}, false)
+		return
+	}
+	fmt.Println(msg)
+}
+
+func (h *scriptHost) emit(envelope actionEnvelope) error {
+	if h.opts.noOutput {
+		return nil
+	}
+	if h.opts.isAgent() {
+		return output.PrintJSON(os.Stdout, envelope, false)
+	}
+	label := envelope.Action.ID
+	if task := taskFromInputValue(envelope.Input); task != "" {
+		label += " " + task
+	}
+	switch {
+	case envelope.DryRun:
+		fmt.Printf("%s: dry run -> %s\n", label, envelope.Shortcut.Name)
+	case envelope.Skipped:
+		fmt.Printf("%s: skipped (%s)\n", label, envelope.Reason)
+	case !envelope.OK:
+		fmt.Printf("%s: failed: %s\n", label, envelope.Error)
+	default:
+		fmt.Printf("%s: ok (%s)\n", label, envelope.Shortcut.Name)
+	}
+	return nil
+}
+
+func taskFromInputValue(input any) string {
+	if payload, ok := input.(map[string]any); ok {
+		if task, ok := payload["task"].(string); ok {
+			return task
+		}
+	}
+	return ""
+}
+
+func jsonMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestRunScriptDryRun(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.json"))
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{
+		"task-list":     {Name: "List Tasks"},
+		"task-status":   {Name: "Task Status"},
+		"task-complete": {Name: "Complete Task"},
+	}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var ran []string
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name)
+		switch name {
+		case "List Tasks":
+			return []byte("Meditate\nRead\n"), nil
+		case "Task Status":
+			if string(input) == `{"task":"Read"}` {
+				return []byte(`{"status":"pending"}`), nil
+			}
+			return []byte(`{"status":"done"}`), nil
+		}
+		return []byte("ok"), nil
+	}
+
+	src := []byte(`
+for task in tasks():
+    if status(task)["status"] == "pending":
+        complete(task)
+`)
+	opts := &rootOptions{noOutput: true}
+	scriptOpts := &scriptOptions{dryRun: true}
+	host := newScriptHost(newActionSession(), "test.star", scriptOpts, opts)
+	if err := runScript(context.Background(), "test.star", src, host, scriptOpts, opts); err != nil {
+		t.Fatalf("runScript: %v", err)
+	}
+	for _, name := range ran {
+		if name == "Complete Task" {
+			t.Fatalf("dry run should not complete tasks: %v", ran)
+		}
+	}
+	if host.summary.Total != 1 || host.summary.OK != 1 {
+		t.Fatalf("unexpected summary: %+v", host.summary)
+	}
+
+	err := runScript(context.Background(), "bad.star", []byte("if"), host, scriptOpts, opts)
+	if code, _ := exitCodeFromError(err); code != ExitCodeUsage {
+		t.Fatalf("expected usage error for syntax error, got %v", err)
+	}
+}
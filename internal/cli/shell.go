@@ -0,0 +1,503 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/output"
+)
+
+const shellHistoryLimit = 500
+
+var errShellQuit = errors.New("quit")
+
+var shellBuiltins = []string{":help", ":reload", ":trace", ":quit"}
+
+// shell keeps one action session alive across lines so discovery, the
+// shortcut list and config are only loaded once (or on :reload).
+type shell struct {
+	opts    *rootOptions
+	flags   map[string]string
+	session *actionSession
+	defs    []discovery.ActionDef
+	tasks   []string
+	trace   string
+	out     io.Writer
+	errOut  io.Writer
+}
+
+func newShellCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Interactive prompt that keeps discovery and shortcuts loaded",
+		Long: "Interactive prompt that keeps discovery, the shortcut list and config loaded between commands.\n\n" +
+			"Lines use the normal command grammar (task-complete --task Read). Built-ins: :reload, :trace on [file] | off,\n" +
+			":help, :quit. With --agent, every input line is a JSON action request (as in st batch) or a built-in,\n" +
+			"answered with exactly one JSON line.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			flags := map[string]string{}
+			cmd.InheritedFlags().Visit(func(f *pflag.Flag) {
+				flags[f.Name] = f.Value.String()
+			})
+			sh := newShell(opts, flags, os.Stdout, os.Stderr)
+			ctx := context.Background()
+			if opts.isAgent() {
+				return sh.serveAgent(ctx, os.Stdin)
+			}
+			inFd, outFd := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+			if isTerminal(inFd) && isTerminal(outFd) {
+				return sh.interactive(ctx, inFd)
+			}
+			return sh.serveLines(ctx, os.Stdin)
+		},
+	}
+}
+
+func newShell(opts *rootOptions, flags map[string]string, out, errOut io.Writer) *shell {
+	return &shell{opts: opts, flags: flags, session: opts.actionSession(), out: out, errOut: errOut}
+}
+
+func (sh *shell) interactive(ctx context.Context, fd int) error {
+	screen := struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}
+	t := term.NewTerminal(screen, "st> ")
+	history := loadShellHistory(shellHistoryPath())
+	t.History = history
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return sh.complete(ctx, line, pos)
+	}
+	fmt.Fprintln(sh.out, "Streaks shell. Type :help for built-ins, :quit or Ctrl-D to exit.")
+	for {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		line, err := t.ReadLine()
+		_ = term.Restore(fd, state)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := sh.execLine(ctx, line); err != nil {
+			if errors.Is(err, errShellQuit) {
+				return nil
+			}
+			sh.printError(err)
+		}
+	}
+}
+
+// serveLines runs newline-separated commands from a non-terminal reader.
+func (sh *shell) serveLines(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if err := sh.execLine(ctx, scanner.Text()); err != nil {
+			if errors.Is(err, errShellQuit) {
+				return nil
+			}
+			sh.printError(err)
+		}
+	}
+	return scanner.Err()
+}
+
+// serveAgent answers each input line with exactly one JSON line.
+func (sh *shell) serveAgent(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			reply, err := sh.builtin(line)
+			if errors.Is(err, errShellQuit) {
+				return output.PrintJSON(sh.out, map[string]any{"ok": true, "builtin": "quit"}, false)
+			}
+			if err != nil {
+				if err := output.PrintJSON(sh.out, errorEnvelope(nil, "", err), false); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := output.PrintJSON(sh.out, reply, false); err != nil {
+				return err
+			}
+			continue
+		}
+		var req actionRequest
+		var envelope actionEnvelope
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			envelope = errorEnvelope(nil, "", exitError(ExitCodeUsage, fmt.Errorf("invalid JSON request: %w", err)))
+		} else if strings.TrimSpace(req.Action) == "" {
+			envelope = errorEnvelope(req.ID, "", exitError(ExitCodeUsage, errors.New("missing action")))
+		} else {
+			req.Trace = sh.trace
+			envelope, _ = sh.session.execute(ctx, req, sh.opts)
+		}
+		if err := output.PrintJSON(sh.out, envelope, false); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (sh *shell) execLine(ctx context.Context, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	if strings.HasPrefix(line, ":") {
+		reply, err := sh.builtin(line)
+		if err != nil {
+			return err
+		}
+		if msg, ok := reply["message"].(string); ok {
+			fmt.Fprintln(sh.out, msg)
+		}
+		return nil
+	}
+	args, err := splitShellLine(line)
+	if err != nil {
+		return exitError(ExitCodeUsage, err)
+	}
+	if len(args) > 0 && args[0] == "st" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	switch args[0] {
+	case "shell", "ui":
+		return exitError(ExitCodeUsage, fmt.Errorf("%s is not available inside st shell", args[0]))
+	}
+
+	root := sh.rootCmd(ctx)
+	if sh.trace != "" && !hasArg(args, "--trace") {
+		if target, _, err := root.Find(args); err == nil && target.Flags().Lookup("trace") != nil {
+			args = append(args, "--trace", sh.trace)
+		}
+	}
+	root.SetArgs(args)
+	// A --folder on this line only applies to it.
+	defer func() { sh.session.folder = sh.opts.folder }()
+	return root.Execute()
+}
+
+// rootCmd builds a fresh command tree for one line, sharing the shell's
+// session and the global flags the shell was started with.
+func (sh *shell) rootCmd(ctx context.Context) *cobra.Command {
+	opts := &rootOptions{session: sh.session, stdinReserved: true}
+	root := buildRootCmd(opts, sh.actionDefs(ctx))
+	for name, value := range sh.flags {
+		_ = root.PersistentFlags().Set(name, value)
+	}
+	return root
+}
+
+func (sh *shell) actionDefs(ctx context.Context) []discovery.ActionDef {
+	if sh.defs == nil {
+		sh.defs = discovery.DefaultActionDefinitions()
+		if os.Getenv(envDisableDiscovery) == "" {
+			disc, err := sh.session.discovery(ctx)
+			sh.defs = actionDefsFromDiscovery(sh.defs, disc, err)
+		}
+	}
+	return sh.defs
+}
+
+func (sh *shell) builtin(line string) (map[string]any, error) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":quit", ":exit", ":q":
+		return nil, errShellQuit
+	case ":help":
+		return map[string]any{"ok": true, "builtin": "help", "message": strings.Join([]string{
+			"Run any st command without the st prefix, e.g. task-complete --task Read",
+			":reload              reload discovery, the shortcut list and config",
+			":trace on [file]     append a JSON trace of every run (default: shell-trace.jsonl in the config dir)",
+			":trace off           stop tracing",
+			":quit                leave the shell",
+		}, "\n")}, nil
+	case ":reload":
+		sh.session = sh.opts.actionSession()
+		sh.defs = nil
+		sh.tasks = nil
+		return map[string]any{"ok": true, "builtin": "reload", "message": "Reloaded discovery, shortcuts and config"}, nil
+	case ":trace":
+		if len(fields) == 1 {
+			return sh.traceReply(), nil
+		}
+		switch fields[1] {
+		case "on":
+			if len(fields) > 2 {
+				sh.trace = fields[2]
+			} else {
+				dir, err := config.Dir()
+				if err != nil {
+					return nil, err
+				}
+				sh.trace = filepath.Join(dir, "shell-trace.jsonl")
+			}
+			return sh.traceReply(), nil
+		case "off":
+			sh.trace = ""
+			return sh.traceReply(), nil
+		}
+		return nil, exitError(ExitCodeUsage, errors.New("usage: :trace on [file] | off"))
+	}
+	return nil, exitError(ExitCodeUsage, fmt.Errorf("unknown built-in %s (try :help)", fields[0]))
+}
+
+func (sh *shell) traceReply() map[string]any {
+	reply := map[string]any{"ok": true, "builtin": "trace", "trace": sh.trace != ""}
+	if sh.trace != "" {
+		reply["file"] = sh.trace
+		reply["message"] = "Tracing to " + sh.trace
+	} else {
+		reply["message"] = "Tracing off"
+	}
+	return reply
+}
+
+func (sh *shell) printError(err error) {
+	if code, inner := exitCodeFromError(err); code != 0 {
+		if !isSilentExit(err) {
+			fmt.Fprintln(sh.errOut, inner.Error())
+		}
+		return
+	}
+	fmt.Fprintln(sh.errOut, err.Error())
+}
+
+// complete handles tab completion for command names, flags, built-ins and
+// task names after --task.
+func (sh *shell) complete(ctx context.Context, line string, pos int) (string, int, bool) {
+	head := line[:pos]
+	start := strings.LastIndex(head, " ") + 1
+	word := head[start:]
+	words := strings.Fields(head[:start])
+	if len(words) > 0 && words[0] == "st" {
+		words = words[1:]
+	}
+
+	var options []string
+	switch {
+	case len(words) == 0:
+		options = append(options, shellBuiltins...)
+		for _, cmd := range sh.rootCmd(ctx).Commands() {
+			if cmd.Name() != "shell" && cmd.Name() != "ui" && !cmd.Hidden {
+				options = append(options, cmd.Name())
+			}
+		}
+	case words[0] == ":trace" && len(words) == 1:
+		options = []string{"on", "off"}
+	case words[len(words)-1] == "--task":
+		for _, task := range sh.taskNames(ctx) {
+			if strings.ContainsAny(task, " \t") {
+				task = `"` + task + `"`
+			}
+			options = append(options, task)
+		}
+		word = strings.TrimPrefix(word, `"`)
+		start = pos - len(word)
+		if strings.HasSuffix(head[:start], `"`) {
+			start--
+		}
+	case strings.HasPrefix(word, "-"):
+		if target, _, err := sh.rootCmd(ctx).Find(words); err == nil {
+			target.Flags().VisitAll(func(f *pflag.Flag) {
+				options = append(options, "--"+f.Name)
+			})
+		}
+	}
+
+	matches := make([]string, 0, len(options))
+	for _, option := range options {
+		if strings.HasPrefix(strings.ToLower(strings.Trim(option, `"`)), strings.ToLower(word)) {
+			matches = append(matches, option)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+	completion := matches[0]
+	if len(matches) == 1 {
+		completion += " "
+	} else {
+		completion = commonPrefix(matches)
+		if len(completion) <= len(word) {
+			return "", 0, false
+		}
+	}
+	newLine := line[:start] + completion + line[pos:]
+	return newLine, start + len(completion), true
+}
+
+func (sh *shell) taskNames(ctx context.Context) []string {
+	if sh.tasks == nil {
+		sh.tasks = []string{}
+		if inv, err := sh.session.invoke(ctx, "task-list", "", "", sh.opts); err == nil {
+			sh.tasks = parseTaskList(inv.Result.Output)
+		}
+	}
+	return sh.tasks
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func hasArg(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitShellLine splits a command line into words, honouring single quotes,
+// double quotes and backslash escapes.
+func splitShellLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func shellHistoryPath() string {
+	dir, err := config.Dir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "shell_history")
+}
+
+// shellHistory is the line-editor history, persisted one entry per line.
+type shellHistory struct {
+	path    string
+	entries []string
+}
+
+func loadShellHistory(path string) *shellHistory {
+	h := &shellHistory{path: path}
+	if path == "" {
+		return h
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[len(h.entries)-shellHistoryLimit:]
+	}
+	return h
+}
+
+func (h *shellHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[1:]
+	}
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, entry)
+}
+
+func (h *shellHistory) Len() int {
+	return len(h.entries)
+}
+
+func (h *shellHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/shortcuts"
+)
+
+func stubShellEnv(t *testing.T) (*[]string, *int) {
+	t.Helper()
+	origRun, origDiscover, origList := runShortcut, discover, listShortcuts
+	t.Cleanup(func() {
+		runShortcut, discover, listShortcuts = origRun, origDiscover, origList
+	})
+	t.Setenv(envDisableDiscovery, "1")
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.json"))
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{"task-list": {Name: "All Tasks"}}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var ran []string
+	lists := 0
+	discover = func(context.Context) (discovery.Discovery, error) {
+		return discovery.Discovery{}, nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		lists++
+		return []shortcuts.Shortcut{{Name: "Mark Read as Complete"}, {Name: "All Tasks"}}, nil
+	}
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name+" "+string(input))
+		if name == "All Tasks" {
+			return []byte("Read\nWalk the Dog\n"), nil
+		}
+		return []byte("ok"), nil
+	}
+	return &ran, &lists
+}
+
+func TestShellLinesShareSession(t *testing.T) {
+	ran, lists := stubShellEnv(t)
+	var errOut bytes.Buffer
+	sh := newShell(&rootOptions{}, map[string]string{"no-output": "true"}, &bytes.Buffer{}, &errOut)
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	script := strings.Join([]string{
+		"task-complete --task Read",
+		":trace on " + trace,
+		`st task-complete --task "Read"`,
+		"shell",
+		"task-complete --task 'unterminated",
+		":quit",
+		"task-complete --task Read",
+	}, "\n")
+	if err := sh.serveLines(context.Background(), strings.NewReader(script)); err != nil {
+		t.Fatalf("serveLines: %v", err)
+	}
+	if len(*ran) != 2 || *lists != 1 {
+		t.Fatalf("expected two runs with one shortcut listing, ran=%v lists=%d", *ran, *lists)
+	}
+	if !strings.Contains(errOut.String(), "not available inside st shell") || !strings.Contains(errOut.String(), "unterminated") {
+		t.Fatalf("expected errors for nested shell and bad quoting, got %q", errOut.String())
+	}
+	if data, err := os.ReadFile(trace); err != nil || strings.Count(string(data), "\n") != 1 {
+		t.Fatalf("expected one traced run after :trace on, got %q (%v)", data, err)
+	}
+
+	if _, err := sh.builtin(":reload"); err != nil {
+		t.Fatalf(":reload: %v", err)
+	}
+	if err := sh.execLine(context.Background(), "task-complete --task Read"); err != nil {
+		t.Fatalf("after reload: %v", err)
+	}
+	if *lists != 2 {
+		t.Fatalf(":reload should reload the shortcut list, lists=%d", *lists)
+	}
+}
+
+func TestShellLineFolder(t *testing.T) {
+	stubShellEnv(t)
+	var folders []string
+	listShortcuts = func(_ context.Context, folder string) ([]shortcuts.Shortcut, error) {
+		folders = append(folders, folder)
+		return []shortcuts.Shortcut{{Name: "Mark Read as Complete"}}, nil
+	}
+	sh := newShell(&rootOptions{folder: "Streaks"}, map[string]string{"no-output": "true", "folder": "Streaks"}, &bytes.Buffer{}, &bytes.Buffer{})
+	script := strings.Join([]string{
+		"shortcuts list",
+		"shortcuts list --folder Other",
+		"shortcuts list --folder Other",
+		"shortcuts list",
+	}, "\n")
+	if err := sh.serveLines(context.Background(), strings.NewReader(script)); err != nil {
+		t.Fatalf("serveLines: %v", err)
+	}
+	if strings.Join(folders, ",") != "Streaks,Other" {
+		t.Fatalf("expected one cached list per folder, listed %v", folders)
+	}
+	if sh.session.folder != "Streaks" {
+		t.Fatalf("a line's --folder should not stick, session folder %q", sh.session.folder)
+	}
+}
+
+func TestShellAgentLoop(t *testing.T) {
+	ran, _ := stubShellEnv(t)
+	var out bytes.Buffer
+	sh := newShell(&rootOptions{agent: true}, nil, &out, &bytes.Buffer{})
+	in := strings.Join([]string{
+		`{"id":1,"action":"task-complete","task":"Read"}`,
+		`not json`,
+		`:trace`,
+		`{"id":2,"action":"task-list"}`,
+	}, "\n")
+	if err := sh.serveAgent(context.Background(), strings.NewReader(in)); err != nil {
+		t.Fatalf("serveAgent: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected one response per request, got %d: %s", len(lines), out.String())
+	}
+	var first, second map[string]any
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	if first["ok"] != true || first["id"] != float64(1) || second["error_code"] != "usage" {
+		t.Fatalf("unexpected responses: %s", out.String())
+	}
+	if len(*ran) != 2 {
+		t.Fatalf("expected two shortcut runs, got %v", *ran)
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	stubShellEnv(t)
+	sh := newShell(&rootOptions{}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	ctx := context.Background()
+
+	cases := []struct {
+		line string
+		want string
+	}{
+		{"task-com", "task-complete "},
+		{":rel", ":reload "},
+		{"task-complete --ta", "task-complete --task "},
+		{"task-complete --task wa", `task-complete --task "Walk the Dog" `},
+		{`task-complete --task "wa`, `task-complete --task "Walk the Dog" `},
+		{":trace o", ":trace o"},
+	}
+	for _, tc := range cases {
+		got, pos, ok := sh.complete(ctx, tc.line, len(tc.line))
+		if !ok {
+			got, pos = tc.line, len(tc.line)
+		}
+		if got != tc.want || pos != len(tc.want) {
+			t.Fatalf("complete(%q) = %q@%d, want %q", tc.line, got, pos, tc.want)
+		}
+	}
+}
+
+func TestSplitShellLine(t *testing.T) {
+	got, err := splitShellLine(`task-complete --task "Walk the Dog" --note "it's" 'a "b"' c\ d`)
+	if err != nil {
+		t.Fatalf("splitShellLine: %v", err)
+	}
+	want := []string{"task-complete", "--task", "Walk the Dog", "--note", "it's", `a "b"`, "c d"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h := loadShellHistory(path)
+	h.Add("task-list")
+	h.Add("task-list")
+	h.Add("today")
+	reloaded := loadShellHistory(path)
+	if reloaded.Len() != 2 || reloaded.At(0) != "today" || reloaded.At(1) != "task-list" {
+		t.Fatalf("unexpected history: %v", reloaded.entries)
+	}
+}
+
+func TestShellSeesRepairedLinks(t *testing.T) {
+	stubShellEnv(t)
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "All Tasks v2", ID: "AAA-111"}}, nil
+	}
+	cfg := config.DefaultConfig()
+	cfg.Mappings["task-list"] = config.ShortcutRef{Name: "All Tasks", ID: "AAA-111"}
+	if _, err := config.Write(cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	sh := newShell(&rootOptions{}, map[string]string{"no-output": "true"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if _, err := sh.session.config(); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := sh.execLine(context.Background(), "links verify --repair"); err != nil {
+		t.Fatalf("links verify --repair: %v", err)
+	}
+	cached, _ := sh.session.config()
+	if ref := cached.Mappings["task-list"]; ref.Name != "All Tasks v2" {
+		t.Fatalf("session should reload the repaired config, got %+v", ref)
+	}
+}
@@ -8,16 +8,26 @@ import (
 	"streaks-cli/internal/shortcuts"
 )
 
-func shortcutCoverage(defs []discovery.ActionDef, disc discovery.Discovery, list []shortcuts.Shortcut, mappings map[string]config.ShortcutRef) ([]string, []string) {
+// shortcutNearMiss is a library shortcut that almost matched a missing action.
+type shortcutNearMiss struct {
+	Action string `json:"action"`
+	shortcutMatch
+}
+
+func shortcutCoverage(defs []discovery.ActionDef, disc discovery.Discovery, list []shortcuts.Shortcut, mappings map[string]config.ShortcutRef) ([]string, []string, []shortcutNearMiss) {
 	available := make([]string, 0)
 	missing := make([]string, 0)
+	var near []shortcutNearMiss
 	for _, def := range defs {
 		if def.Transport != discovery.TransportShortcuts {
 			continue
 		}
 		if ref, ok := mappings[def.ID]; ok {
-			candidate := shortcutLabel(ref)
-			if candidate != "" && matchShortcutName(list, []string{candidate}) != "" {
+			var mapped []string
+			for _, cand := range mappingCandidates(ref, sourceMapping) {
+				mapped = append(mapped, cand.Name)
+			}
+			if matchShortcutName(list, mapped) != "" {
 				available = append(available, def.ID)
 				continue
 			}
@@ -28,11 +38,15 @@ func shortcutCoverage(defs []discovery.ActionDef, disc discovery.Discovery, list
 		}
 		if matchShortcutName(list, candidates) != "" {
 			available = append(available, def.ID)
-		} else {
-			missing = append(missing, def.ID)
+			continue
+		}
+		missing = append(missing, def.ID)
+		for _, miss := range nearShortcutMisses(list, candidates, false) {
+			near = append(near, shortcutNearMiss{Action: def.ID, shortcutMatch: miss})
 		}
 	}
 	sort.Strings(available)
 	sort.Strings(missing)
-	return available, missing
+	sort.SliceStable(near, func(i, j int) bool { return near[i].Action < near[j].Action })
+	return available, missing, near
 }
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/shortcuts"
+)
+
+// shortcutActionID is the envelope action.id for st shortcuts run.
+const shortcutActionID = "shortcut"
+
+// matchContains marks find results whose name contains the query.
+const matchContains = "contains"
+
+type shortcutFindResult struct {
+	Name  string  `json:"name"`
+	ID    string  `json:"id,omitempty"`
+	Match string  `json:"match"`
+	Score float64 `json:"score"`
+}
+
+func newShortcutsCmd(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shortcuts",
+		Short: "List, find and run any shortcut",
+	}
+	cmd.AddCommand(newShortcutsListCmd(opts))
+	cmd.AddCommand(newShortcutsFindCmd(opts))
+	cmd.AddCommand(newShortcutsRunCmd(opts))
+	return cmd
+}
+
+func newShortcutsListCmd(opts *rootOptions) *cobra.Command {
+	var filter string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List shortcuts in the library (or the --folder / shortcuts_folder folder)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			list, err := opts.actionSession().shortcuts(context.Background())
+			if err != nil {
+				return exitError(ExitCodeShortcutsMissing, err)
+			}
+			if filter = normalizeShortcutName(filter); filter != "" {
+				filtered := list[:0:0]
+				for _, sc := range list {
+					if strings.Contains(normalizeShortcutName(sc.Name), filter) {
+						filtered = append(filtered, sc)
+					}
+				}
+				list = filtered
+			}
+			return printShortcutList(list, opts)
+		},
+	}
+	cmd.Flags().StringVar(&filter, "filter", "", "Only list shortcuts whose name contains this text (case-insensitive)")
+	return cmd
+}
+
+func newShortcutsFindCmd(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find <query>",
+		Short: "Find shortcuts by exact, partial or fuzzy name match",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			query := strings.Join(args, " ")
+			list, err := opts.actionSession().shortcuts(context.Background())
+			if err != nil {
+				return exitError(ExitCodeShortcutsMissing, err)
+			}
+			results := findShortcuts(list, query)
+			if err := printShortcutFind(results, opts); err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				return exitError(ExitCodeShortcutMissing, fmt.Errorf("no shortcut matches %q", query))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newShortcutsRunCmd(opts *rootOptions) *cobra.Command {
+	cmdOpts := &actionCmdOptions{}
+	cmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run any shortcut with the action retry, timeout, trace and envelope handling",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAnyShortcut(context.Background(), args[0], cmdOpts, opts)
+		},
+	}
+	cmd.Flags().StringVar(&cmdOpts.input, "input", "", "Raw JSON input to pass to the shortcut, @file to read a file, - for stdin")
+	cmd.Flags().StringVar(&cmdOpts.inputText, "input-text", "", "Plain text input to pass to the shortcut")
+	cmd.Flags().StringArrayVar(&cmdOpts.inputFiles, "input-file", nil, "File to pass to the shortcut after the input (repeatable)")
+	cmd.Flags().BoolVar(&cmdOpts.stdin, "stdin", false, "Read input from stdin (same as --input -)")
+	cmd.Flags().StringVar(&cmdOpts.save, "save", "", "Save the output file to this path (a directory when there are several)")
+	cmd.Flags().BoolVar(&cmdOpts.dryRun, "dry-run", false, "Print the command and payload without running")
+	cmd.Flags().StringVar(&cmdOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
+	return cmd
+}
+
+func runAnyShortcut(ctx context.Context, name string, cmdOpts *actionCmdOptions, opts *rootOptions) error {
+	input, err := buildActionInput(discovery.ActionDef{ID: shortcutActionID}, cmdOpts, opts)
+	if err != nil {
+		return exitError(ExitCodeUsage, err)
+	}
+	resolution := shortcutResolution{Shortcut: name, Source: sourceFlag}
+	if cmdOpts.dryRun {
+		plan, err := buildDryRunPlan(shortcutActionID, resolution, input, cmdOpts, opts)
+		if err != nil {
+			return err
+		}
+		return printDryRun(opts, cmdOpts, plan)
+	}
+	name, result, err := runResolvedShortcut(ctx, shortcutActionID, resolution, input, cmdOpts, opts)
+	if err != nil {
+		return err
+	}
+	return emitActionOutput(shortcutActionID, name, input, result, nil, opts)
+}
+
+// findShortcuts ranks library shortcuts against query: exact and normalised
+// matches first, then names containing the query, then fuzzy matches down to
+// nearMissThreshold.
+func findShortcuts(list []shortcuts.Shortcut, query string) []shortcutFindResult {
+	key := normalizeShortcutName(query)
+	if key == "" {
+		return nil
+	}
+	rank := map[string]int{matchExact: 0, matchNormalized: 1, matchUnicode: 2, matchContains: 3, matchFuzzy: 4}
+	var results []shortcutFindResult
+	for _, sc := range list {
+		name := normalizeShortcutName(sc.Name)
+		result := shortcutFindResult{Name: sc.Name, ID: sc.ID, Score: 1}
+		switch {
+		case sc.Name == query:
+			result.Match = matchExact
+		case strings.EqualFold(strings.TrimSpace(sc.Name), strings.TrimSpace(query)):
+			result.Match = matchNormalized
+		case name == key:
+			result.Match = matchUnicode
+		case strings.Contains(name, key):
+			result.Match = matchContains
+			result.Score = nameSimilarity(name, key)
+		default:
+			result.Match = matchFuzzy
+			result.Score = nameSimilarity(name, key)
+			if result.Score < nearMissThreshold {
+				continue
+			}
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if rank[results[i].Match] != rank[results[j].Match] {
+			return rank[results[i].Match] < rank[results[j].Match]
+		}
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+func printShortcutList(list []shortcuts.Shortcut, opts *rootOptions) error {
+	if opts.noOutput {
+		return nil
+	}
+	if opts.isAgent() {
+		for _, sc := range list {
+			if err := output.PrintJSON(os.Stdout, sc, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID")
+	for _, sc := range list {
+		fmt.Fprintf(w, "%s\t%s\n", sc.Name, sc.ID)
+	}
+	return w.Flush()
+}
+
+func printShortcutFind(results []shortcutFindResult, opts *rootOptions) error {
+	if opts.noOutput {
+		return nil
+	}
+	if opts.isAgent() {
+		for _, result := range results {
+			if err := output.PrintJSON(os.Stdout, result, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMATCH\tSCORE\tID")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\n", result.Name, strings.ReplaceAll(result.Match, "_", " "), result.Score, result.ID)
+	}
+	return w.Flush()
+}
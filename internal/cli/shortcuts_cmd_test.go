@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestFindShortcuts(t *testing.T) {
+	list := []shortcuts.Shortcut{
+		{Name: "Morning Routine", ID: "AAA"},
+		{Name: "log water", ID: "BBB"},
+		{Name: "Log Water", ID: "CCC"},
+		{Name: "Log Waterr", ID: "DDD"},
+		{Name: "Weather", ID: "EEE"},
+	}
+	results := findShortcuts(list, "Log Water")
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Name+"="+result.Match)
+	}
+	want := "Log Water=exact,log water=normalized,Log Waterr=contains"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("results = %v, want %s", got, want)
+	}
+	if results := findShortcuts(list, "Mornin Routine"); len(results) != 1 || results[0].Match != matchFuzzy {
+		t.Fatalf("expected a fuzzy match, got %+v", results)
+	}
+}
+
+func TestShortcutsListUsesConfigFolder(t *testing.T) {
+	origList := listShortcuts
+	defer func() { listShortcuts = origList }()
+	var folders []string
+	listShortcuts = func(_ context.Context, folder string) ([]shortcuts.Shortcut, error) {
+		folders = append(folders, folder)
+		return []shortcuts.Shortcut{{Name: "Log Water", ID: "AAA"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	if _, err := config.Write(config.Config{ShortcutsFolder: "Streaks"}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	captureCommand(t, "shortcuts", "list")
+	captureCommand(t, "shortcuts", "find", "log water")
+	captureCommand(t, "--folder", "Other", "shortcuts", "list")
+	if strings.Join(folders, ",") != "Streaks,Streaks,Other" {
+		t.Fatalf("listed folders %v, want the config folder unless --folder is set", folders)
+	}
+}
+
+func TestShortcutsRun(t *testing.T) {
+	origRun := runShortcut
+	origList := listShortcuts
+	defer func() {
+		runShortcut = origRun
+		listShortcuts = origList
+	}()
+	var called, sent string
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		called, sent = name, string(input)
+		return []byte("hello\n"), nil
+	}
+	listShortcuts = func(context.Context, string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Say Hello", ID: "AAA"}, {Name: "Other", ID: "BBB"}}, nil
+	}
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	out := captureCommand(t, "--agent", "shortcuts", "run", "Say Hello", "--input", "world")
+	if called != "Say Hello" || sent != "world" {
+		t.Fatalf("ran %q with %q", called, sent)
+	}
+	var envelope actionEnvelope
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if envelope.Action.ID != shortcutActionID || envelope.Shortcut.Name != "Say Hello" || !envelope.OK {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	var runOpts shortcuts.RunOptions
+	runShortcut = func(_ context.Context, name string, input []byte, opts shortcuts.RunOptions) ([]byte, error) {
+		sent, runOpts = string(input), opts
+		return []byte("ok"), nil
+	}
+	image := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(image, []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureCommand(t, "shortcuts", "run", "Say Hello", "--input-text", "caption", "--input-file", image, "--input-file", image)
+	if sent != "caption" || runOpts.InputType != shortcuts.InputText || len(runOpts.InputFiles) != 2 {
+		t.Fatalf("ran with %q %+v", sent, runOpts)
+	}
+
+	out = captureCommand(t, "--agent", "shortcuts", "run", "Say Hello", "--input-file", image, "--dry-run")
+	var plan dryRunPlan
+	if err := json.Unmarshal([]byte(out), &plan); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if got := strings.Join(plan.Argv[1:], " "); got != "run Say Hello --input-path "+image+" --output-path <output-dir> --output-type public.plain-text" {
+		t.Fatalf("unexpected argv: %s", got)
+	}
+
+	out = captureCommand(t, "--agent", "shortcuts", "list", "--filter", "hello")
+	if strings.Count(out, "\n") != 1 || !strings.Contains(out, `"name":"Say Hello"`) {
+		t.Fatalf("unexpected list output: %q", out)
+	}
+}
+
+func captureCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	origStdout, origStdin := os.Stdout, os.Stdin
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	os.Stdin, _ = os.Open(os.DevNull)
+	cmd := newRootCmd()
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	_ = w.Close()
+	_ = os.Stdin.Close()
+	os.Stdout, os.Stdin = origStdout, origStdin
+	out, _ := io.ReadAll(r)
+	_ = r.Close()
+	if err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return string(out)
+}
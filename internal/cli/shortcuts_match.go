@@ -1,19 +1,60 @@
 package cli
 
 import (
+	"sort"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 
 	"streaks-cli/internal/shortcuts"
 )
 
+// Match kinds reported by matchShortcut, strongest first.
+const (
+	matchExact        = "exact"
+	matchNormalized   = "normalized"
+	matchID           = "id"
+	matchIDNormalized = "id_normalized"
+	matchUnicode      = "unicode"
+	matchFuzzy        = "fuzzy"
+)
+
+// fuzzyMatchThreshold is the lowest score accepted as a fuzzy match with
+// --fuzzy; near misses down to nearMissThreshold are only reported.
+const (
+	fuzzyMatchThreshold = 0.85
+	nearMissThreshold   = 0.6
+	maxNearMisses       = 3
+)
+
+// shortcutMatch is a library shortcut found for a candidate name. Name is the
+// library name, or the identifier for id matches.
+type shortcutMatch struct {
+	Name      string  `json:"shortcut"`
+	Candidate string  `json:"candidate"`
+	Kind      string  `json:"match"`
+	Score     float64 `json:"score"`
+}
+
 func matchShortcutName(shortcuts []shortcuts.Shortcut, candidates []string) string {
+	return matchShortcut(shortcuts, candidates, false).Name
+}
+
+// matchShortcut returns the first candidate found in the library. Candidates
+// are tried in order with exact, case-insensitive, identifier and Unicode
+// normalised comparisons. Only with fuzzy, and only when none of those
+// matches, is the best fuzzy match at or above fuzzyMatchThreshold used;
+// otherwise close names are left to nearShortcutMisses as suggestions.
+func matchShortcut(shortcuts []shortcuts.Shortcut, candidates []string, fuzzy bool) shortcutMatch {
 	if len(shortcuts) == 0 || len(candidates) == 0 {
-		return ""
+		return shortcutMatch{}
 	}
 	exact := make(map[string]string, len(shortcuts))
 	normalized := make(map[string]string, len(shortcuts))
 	idExact := make(map[string]string, len(shortcuts))
 	idNormalized := make(map[string]string, len(shortcuts))
+	folded := make(map[string]string, len(shortcuts))
 	for _, sc := range shortcuts {
 		exact[sc.Name] = sc.Name
 		normalized[strings.ToLower(strings.TrimSpace(sc.Name))] = sc.Name
@@ -21,20 +62,174 @@ func matchShortcutName(shortcuts []shortcuts.Shortcut, candidates []string) stri
 			idExact[sc.ID] = sc.ID
 			idNormalized[strings.ToLower(strings.TrimSpace(sc.ID))] = sc.ID
 		}
+		if key := normalizeShortcutName(sc.Name); key != "" {
+			if _, ok := folded[key]; !ok {
+				folded[key] = sc.Name
+			}
+		}
+	}
+	found := func(name, cand, kind string) shortcutMatch {
+		return shortcutMatch{Name: name, Candidate: cand, Kind: kind, Score: 1}
 	}
 	for _, cand := range candidates {
 		if name, ok := exact[cand]; ok {
-			return name
+			return found(name, cand, matchExact)
 		}
 		if name, ok := normalized[strings.ToLower(strings.TrimSpace(cand))]; ok {
-			return name
+			return found(name, cand, matchNormalized)
 		}
 		if id, ok := idExact[cand]; ok {
-			return id
+			return found(id, cand, matchID)
 		}
 		if id, ok := idNormalized[strings.ToLower(strings.TrimSpace(cand))]; ok {
-			return id
+			return found(id, cand, matchIDNormalized)
+		}
+		if name, ok := folded[normalizeShortcutName(cand)]; ok {
+			return found(name, cand, matchUnicode)
+		}
+	}
+	if !fuzzy {
+		return shortcutMatch{}
+	}
+	if best := scoreShortcuts(shortcuts, candidates); len(best) > 0 && best[0].Score >= fuzzyMatchThreshold {
+		return best[0]
+	}
+	return shortcutMatch{}
+}
+
+// nearShortcutMisses returns the closest library shortcuts that were not
+// matched, best first: everything down to nearMissThreshold, or only those
+// below fuzzyMatchThreshold when fuzzy matching is on.
+func nearShortcutMisses(shortcuts []shortcuts.Shortcut, candidates []string, fuzzy bool) []shortcutMatch {
+	var misses []shortcutMatch
+	for _, match := range scoreShortcuts(shortcuts, candidates) {
+		if (fuzzy && match.Score >= fuzzyMatchThreshold) || match.Score < nearMissThreshold {
+			continue
+		}
+		misses = append(misses, match)
+		if len(misses) == maxNearMisses {
+			break
+		}
+	}
+	return misses
+}
+
+// scoreShortcuts scores every library shortcut against its closest candidate
+// and returns them best first; ties keep candidate priority.
+func scoreShortcuts(shortcuts []shortcuts.Shortcut, candidates []string) []shortcutMatch {
+	keys := make([]string, len(candidates))
+	for i, cand := range candidates {
+		keys[i] = normalizeShortcutName(cand)
+	}
+	type scored struct {
+		match shortcutMatch
+		rank  int
+	}
+	var all []scored
+	for _, sc := range shortcuts {
+		name := normalizeShortcutName(sc.Name)
+		if name == "" {
+			continue
+		}
+		best := scored{rank: -1}
+		for i, key := range keys {
+			if key == "" {
+				continue
+			}
+			if score := nameSimilarity(name, key); best.rank < 0 || score > best.match.Score {
+				best = scored{match: shortcutMatch{Name: sc.Name, Candidate: candidates[i], Kind: matchFuzzy, Score: score}, rank: i}
+			}
+		}
+		if best.rank >= 0 {
+			all = append(all, best)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].match.Score != all[j].match.Score {
+			return all[i].match.Score > all[j].match.Score
+		}
+		return all[i].rank < all[j].rank
+	})
+	out := make([]shortcutMatch, len(all))
+	for i, s := range all {
+		out[i] = s.match
+	}
+	return out
+}
+
+// normalizeShortcutName folds a shortcut name for comparison: NFC, smart
+// punctuation replaced by ASCII, emoji dropped, whitespace collapsed and
+// lower-cased.
+func normalizeShortcutName(name string) string {
+	name = norm.NFC.String(name)
+	var b strings.Builder
+	space := false
+	for _, r := range name {
+		switch {
+		case strings.ContainsRune("‘’‚‛′`", r):
+			r = '\''
+		case strings.ContainsRune("“”„‟″", r):
+			r = '"'
+		case strings.ContainsRune("‐‑‒–—―", r):
+			r = '-'
+		case r == '…':
+			b.WriteString("...")
+			space = false
+			continue
+		}
+		if isEmojiRune(r) {
+			continue
+		}
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func isEmojiRune(r rune) bool {
+	switch {
+	case unicode.Is(unicode.So, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cf, r):
+		return true
+	case unicode.Is(unicode.Variation_Selector, r):
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return true
+	}
+	return false
+}
+
+// nameSimilarity is 1 minus the rune edit distance over the longer length.
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
 		}
+		prev, curr = curr, prev
 	}
-	return ""
+	return 1 - float64(prev[len(rb)])/float64(longest)
 }
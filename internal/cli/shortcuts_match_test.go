@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"testing"
+
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestMatchShortcutUnicode(t *testing.T) {
+	library := []shortcuts.Shortcut{
+		{Name: "Don’t Break the Chain"},
+		{Name: "Caf\u00e9 Visit"},
+		{Name: "✅ Complete  Task"},
+		{Name: "Get Task", ID: "1A2B-3C"},
+	}
+	cases := []struct {
+		candidate string
+		want      string
+		kind      string
+	}{
+		{"Get Task", "Get Task", matchExact},
+		{" get task ", "Get Task", matchNormalized},
+		{"1a2b-3c", "1A2B-3C", matchIDNormalized},
+		{"Don't Break the Chain", "Don’t Break the Chain", matchUnicode},
+		{"Cafe\u0301 Visit", "Caf\u00e9 Visit", matchUnicode},
+		{"Complete Task", "✅ Complete  Task", matchUnicode},
+		{"Complete Tasks", "✅ Complete  Task", matchFuzzy},
+	}
+	for _, tc := range cases {
+		got := matchShortcut(library, []string{tc.candidate}, true)
+		if got.Name != tc.want || got.Kind != tc.kind {
+			t.Fatalf("%q: got %+v, want %s (%s)", tc.candidate, got, tc.want, tc.kind)
+		}
+		if tc.kind != matchFuzzy && got.Score != 1 {
+			t.Fatalf("%q: non-fuzzy matches score 1, got %v", tc.candidate, got.Score)
+		}
+	}
+}
+
+func TestMatchShortcutPrefersCandidateOrder(t *testing.T) {
+	library := []shortcuts.Shortcut{{Name: "Complete Task"}, {Name: "complete read"}}
+	got := matchShortcut(library, []string{"Complete Read", "Complete Task"}, false)
+	if got.Name != "complete read" || got.Kind != matchNormalized {
+		t.Fatalf("first candidate should win, got %+v", got)
+	}
+}
+
+func TestNearShortcutMisses(t *testing.T) {
+	library := []shortcuts.Shortcut{{Name: "Start Timer"}, {Name: "Export Data"}}
+	if got := matchShortcut(library, []string{"Start Task Timer"}, true); got.Name != "" {
+		t.Fatalf("expected no match, got %+v", got)
+	}
+	misses := nearShortcutMisses(library, []string{"Start Task Timer"}, true)
+	if len(misses) != 1 || misses[0].Name != "Start Timer" || misses[0].Score >= fuzzyMatchThreshold {
+		t.Fatalf("unexpected near misses: %+v", misses)
+	}
+}
+
+func TestFuzzyMatchNeedsOptIn(t *testing.T) {
+	library := []shortcuts.Shortcut{{Name: "Complete Fun"}, {Name: "Uncomplete Task"}}
+	for _, cand := range []string{"Complete Run", "Complete Task"} {
+		if got := matchShortcut(library, []string{cand}, false); got.Name != "" {
+			t.Fatalf("%q: fuzzy match without opt-in: %+v", cand, got)
+		}
+		misses := nearShortcutMisses(library, []string{cand}, false)
+		if len(misses) == 0 || misses[0].Kind != matchFuzzy {
+			t.Fatalf("%q: close names should be suggested, got %+v", cand, misses)
+		}
+	}
+	if got := matchShortcut(library, []string{"Complete Run"}, true); got.Name != "Complete Fun" {
+		t.Fatalf("--fuzzy should accept the close match, got %+v", got)
+	}
+}
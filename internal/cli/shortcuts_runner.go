@@ -7,19 +7,64 @@ import (
 	"strings"
 	"time"
 
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/discovery"
 	"streaks-cli/internal/shortcuts"
 )
 
+const defaultOutputType = "public.plain-text"
+
 type runResult struct {
 	Output   []byte
 	Attempts int
 	Duration time.Duration
+	// Strategy is the resolution strategy that picked the shortcut.
+	Strategy string
+	// Extract turns Output into the envelope result when set.
+	Extract *config.Extractor
+}
+
+// outputSpec is the output type and extractor for one shortcut.
+type outputSpec struct {
+	Type    string
+	Extract *config.Extractor
+}
+
+// actionOutput is the built-in output default of an action. The defaults are
+// fixed at build time; TestActionOutputDefaults checks that every extractor
+// parses, so a bad one is a test failure rather than a silently dropped
+// extractor.
+func actionOutput(def discovery.ActionDef) outputSpec {
+	spec, _ := parseActionOutput(def)
+	return spec
+}
+
+func parseActionOutput(def discovery.ActionDef) (outputSpec, error) {
+	spec := outputSpec{Type: def.OutputType}
+	if def.Extract == "" {
+		return spec, nil
+	}
+	extractor, err := config.ParseExtractor(def.Extract)
+	if err != nil {
+		return spec, fmt.Errorf("action %s default extractor: %w", def.ID, err)
+	}
+	spec.Extract = &extractor
+	return spec, nil
 }
 
-func runShortcutWithRetry(ctx context.Context, name string, input []byte, opts *rootOptions) (runResult, error) {
+// runShortcutWithRetry runs name with runOpts.OutputType as the preferred
+// output type, retrying failures per --retries.
+func runShortcutWithRetry(ctx context.Context, name string, input []byte, runOpts shortcuts.RunOptions, opts *rootOptions) (runResult, error) {
 	start := time.Now()
+	runOpts.OutputType = shortcutsOutputType(opts, runOpts.OutputType)
+	if opts != nil && runOpts.OutputDir == "" {
+		runOpts.OutputDir = opts.outputDir
+	}
+	if opts != nil {
+		runOpts.Overwrite = opts.overwrite
+	}
 	if opts == nil {
-		out, err := runShortcut(ctx, name, input, shortcuts.RunOptions{OutputType: shortcutsOutputType(opts)})
+		out, err := runShortcut(ctx, name, input, runOpts)
 		return runResult{Output: out, Attempts: 1, Duration: time.Since(start)}, err
 	}
 	attempts := opts.retries + 1
@@ -29,7 +74,7 @@ func runShortcutWithRetry(ctx context.Context, name string, input []byte, opts *
 	}
 	var lastErr error
 	for attempt := 1; attempt <= attempts; attempt++ {
-		out, err := runShortcut(ctx, name, input, shortcuts.RunOptions{OutputType: shortcutsOutputType(opts)})
+		out, err := runShortcut(ctx, name, input, runOpts)
 		if err == nil {
 			return runResult{Output: out, Attempts: attempt, Duration: time.Since(start)}, nil
 		}
@@ -49,12 +94,14 @@ func runShortcutWithRetry(ctx context.Context, name string, input []byte, opts *
 	return runResult{Attempts: attempts, Duration: time.Since(start)}, fmt.Errorf("shortcuts run failed after %d attempts: %w", attempts, lastErr)
 }
 
-func shortcutsOutputType(opts *rootOptions) string {
-	if opts == nil {
-		return "public.plain-text"
+// shortcutsOutputType is --shortcuts-output (or its env var) when set, else
+// preferred (from the mapping or action default), else plain text.
+func shortcutsOutputType(opts *rootOptions, preferred string) string {
+	if opts != nil && strings.TrimSpace(opts.shortcutsOutput) != "" {
+		return strings.TrimSpace(opts.shortcutsOutput)
 	}
-	if strings.TrimSpace(opts.shortcutsOutput) == "" {
-		return "public.plain-text"
+	if strings.TrimSpace(preferred) != "" {
+		return strings.TrimSpace(preferred)
 	}
-	return strings.TrimSpace(opts.shortcutsOutput)
+	return defaultOutputType
 }
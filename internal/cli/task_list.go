@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// parseTaskList extracts task titles from task-list output: a JSON array of
+// strings or task dictionaries, a dictionary with a "tasks" key, or one title
+// per line of plain text.
+func parseTaskList(out []byte) []string {
+	trimmed := bytes.TrimSpace(out)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	var payload any
+	if err := json.Unmarshal(trimmed, &payload); err == nil {
+		return uniqueTitles(titlesFromJSON(payload))
+	}
+	titles := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimLeft(line, "-•*"))
+		if line != "" {
+			titles = append(titles, line)
+		}
+	}
+	return uniqueTitles(titles)
+}
+
+func titlesFromJSON(payload any) []string {
+	switch v := payload.(type) {
+	case string:
+		return parseTaskList([]byte(v))
+	case []any:
+		titles := make([]string, 0, len(v))
+		for _, item := range v {
+			titles = append(titles, titlesFromJSON(item)...)
+		}
+		return titles
+	case map[string]any:
+		for _, key := range []string{"tasks", "result", "items"} {
+			if nested, ok := v[key]; ok {
+				return titlesFromJSON(nested)
+			}
+		}
+		for _, key := range []string{"title", "name", "task"} {
+			if title, ok := v[key].(string); ok && strings.TrimSpace(title) != "" {
+				return []string{strings.TrimSpace(title)}
+			}
+		}
+	}
+	return nil
+}
+
+func uniqueTitles(titles []string) []string {
+	seen := make(map[string]struct{}, len(titles))
+	out := make([]string, 0, len(titles))
+	for _, title := range titles {
+		if _, ok := seen[title]; ok {
+			continue
+		}
+		seen[title] = struct{}{}
+		out = append(out, title)
+	}
+	return out
+}
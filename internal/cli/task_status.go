@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"streaks-cli/internal/output"
+)
+
+const (
+	taskStateDone    = "done"
+	taskStatePending = "pending"
+	taskStateMissed  = "missed"
+	taskStateUnknown = "unknown"
+)
+
+type taskStatus struct {
+	Task          string `json:"task,omitempty"`
+	State         string `json:"status"`
+	CurrentStreak *int   `json:"current_streak,omitempty"`
+	TimerRunning  *bool  `json:"timer_running,omitempty"`
+	Paused        bool   `json:"paused,omitempty"`
+}
+
+// parseTaskStatus reads the output of a task-status shortcut. Wrappers return
+// either a JSON dictionary with the Streaks task fields, "key: value" text, or
+// a bare status such as "Done". Anything else, including sentences that
+// mention the task, is unknown rather than guessed.
+func parseTaskStatus(out []byte) taskStatus {
+	fields := statusFields(out)
+	status := taskStatus{State: taskStateUnknown}
+	if len(fields) > 0 {
+		status.Task = firstField(fields, "title", "task", "name")
+		status.State = stateFromFields(fields)
+		if streak, err := strconv.Atoi(strings.TrimSpace(firstField(fields, "current_streak", "streak"))); err == nil {
+			status.CurrentStreak = &streak
+		}
+		if value := firstField(fields, "timer_is_timing", "timer_running", "timer"); value != "" {
+			if running, ok := parseStatusBool(value); ok {
+				status.TimerRunning = &running
+			}
+		}
+		if paused, ok := parseStatusBool(firstField(fields, "is_paused", "paused")); ok {
+			status.Paused = paused
+		}
+		// Structured output is only read through its status fields; the
+		// title and other values are never scanned for status words.
+		return status
+	}
+	status.State = stateFromPhrase(string(out))
+	return status
+}
+
+func statusFields(out []byte) map[string]string {
+	trimmed := bytes.TrimSpace(out)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	var payload any
+	if err := json.Unmarshal(trimmed, &payload); err == nil {
+		if items, ok := payload.([]any); ok && len(items) > 0 {
+			payload = items[0]
+		}
+		if dict, ok := payload.(map[string]any); ok {
+			if nested, ok := dict["result"].(map[string]any); ok {
+				dict = nested
+			}
+			fields := make(map[string]string, len(dict))
+			for key, value := range dict {
+				fields[normalizeStatusKey(key)] = stringifyStatusValue(value)
+			}
+			return fields
+		}
+		return nil
+	}
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		sep := strings.IndexAny(line, ":=")
+		if sep <= 0 {
+			continue
+		}
+		key := normalizeStatusKey(line[:sep])
+		if key == "" {
+			continue
+		}
+		fields[key] = strings.TrimSpace(line[sep+1:])
+	}
+	return fields
+}
+
+func normalizeStatusKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	key = strings.Trim(key, "-•* \"")
+	return strings.Join(strings.FieldsFunc(key, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "_")
+}
+
+func stringifyStatusValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func firstField(fields map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func stateFromFields(fields map[string]string) string {
+	if done, ok := parseStatusBool(firstField(fields, "is_complete", "completed", "done")); ok && done {
+		return taskStateDone
+	}
+	if missed, ok := parseStatusBool(firstField(fields, "is_missed", "missed")); ok && missed {
+		return taskStateMissed
+	}
+	if value := firstField(fields, "today_status", "status", "state"); value != "" {
+		if state := stateFromText(value); state != taskStateUnknown {
+			return state
+		}
+	}
+	if done, ok := parseStatusBool(firstField(fields, "is_complete", "completed", "done")); ok && !done {
+		return taskStatePending
+	}
+	return taskStateUnknown
+}
+
+// statusPhrases are the whole-word phrases read as each state, pending first
+// so "not complete" is not taken for complete.
+var statusPhrases = []struct {
+	state   string
+	phrases []string
+}{
+	{taskStatePending, []string{"not complete", "not completed", "not done", "incomplete", "pending", "to do", "todo", "due", "remaining"}},
+	{taskStateMissed, []string{"missed", "miss"}},
+	{taskStateDone, []string{"complete", "completed", "done", "finished"}},
+}
+
+// stateFromText reads a status value such as "Incomplete" or "Done today". It
+// matches whole words, so "Mission" is not "miss" and "residue" is not "due".
+func stateFromText(text string) string {
+	words := statusWords(text)
+	if len(words) == 0 {
+		return taskStateUnknown
+	}
+	padded := " " + strings.Join(words, " ") + " "
+	for _, group := range statusPhrases {
+		for _, phrase := range group.phrases {
+			if strings.Contains(padded, " "+phrase+" ") {
+				return group.state
+			}
+		}
+	}
+	return taskStateUnknown
+}
+
+// stateFromPhrase reads output that is nothing but a status phrase.
+func stateFromPhrase(text string) string {
+	joined := strings.Join(statusWords(text), " ")
+	for _, group := range statusPhrases {
+		for _, phrase := range group.phrases {
+			if joined == phrase {
+				return group.state
+			}
+		}
+	}
+	return taskStateUnknown
+}
+
+func statusWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func parseStatusBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "y", "on":
+		return true, true
+	case "0", "false", "no", "n", "off":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+type statusCheck struct {
+	Task     string `json:"task,omitempty"`
+	Status   string `json:"status"`
+	Expected string `json:"expected"`
+	Match    bool   `json:"match"`
+	Shortcut string `json:"shortcut,omitempty"`
+}
+
+func statusExpectation(cmdOpts *actionCmdOptions) (string, error) {
+	expect := strings.ToLower(strings.TrimSpace(cmdOpts.expect))
+	if expect == "" {
+		if !cmdOpts.check {
+			return "", nil
+		}
+		return taskStateDone, nil
+	}
+	switch expect {
+	case taskStateDone, taskStatePending, taskStateMissed:
+		return expect, nil
+	default:
+		return "", fmt.Errorf("invalid --expect %q (use done, pending, or missed)", cmdOpts.expect)
+	}
+}
+
+func checkTaskStatus(task, shortcutName string, result runResult, expect string, opts *rootOptions) error {
+	status := parseTaskStatus(result.Output)
+	check := statusCheck{
+		Task:     task,
+		Status:   status.State,
+		Expected: expect,
+		Match:    status.State == expect,
+		Shortcut: shortcutName,
+	}
+	if opts != nil && opts.verbose && !opts.noOutput {
+		if opts.isAgent() {
+			if err := output.PrintJSON(os.Stdout, check, false); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s: %s (expected %s)\n", check.Task, check.Status, check.Expected)
+		}
+	}
+	if status.State == taskStateUnknown {
+		return silentExitError(ExitCodeStatusUnknown, errors.New("could not determine task status"))
+	}
+	if !check.Match {
+		return silentExitError(ExitCodeCheckFailed, fmt.Errorf("task status is %s, expected %s", status.State, expect))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimeBound accepts absolute dates (2006-01-02), RFC3339 timestamps,
+// the words today/yesterday, and relative spans such as 90m, 12h, 7d or 2w.
+// When endOfDay is set, bare dates resolve to the start of the following day
+// so they can be used as an exclusive upper bound.
+func parseTimeBound(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty time value")
+	}
+	startOfDay := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		if endOfDay {
+			return day.AddDate(0, 0, 1)
+		}
+		return day
+	}
+	switch strings.ToLower(value) {
+	case "today":
+		return startOfDay(now), nil
+	case "yesterday":
+		return startOfDay(now.AddDate(0, 0, -1)), nil
+	case "now":
+		return now, nil
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return startOfDay(day), nil
+	}
+	if span, err := parseSpan(value); err == nil {
+		return now.Add(-span), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+func parseSpan(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid span %q", value)
+	}
+	unit := value[len(value)-1]
+	switch unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid span %q", value)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	span, err := time.ParseDuration(value)
+	if err != nil || span < 0 {
+		return 0, fmt.Errorf("invalid span %q", value)
+	}
+	return span, nil
+}
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/output"
+)
+
+var (
+	timerNow   = time.Now
+	timerAfter = time.After
+)
+
+type timerRunOptions struct {
+	task     string
+	duration time.Duration
+	breakDur time.Duration
+	cycles   int
+	tick     time.Duration
+	trace    string
+}
+
+type timerEvent struct {
+	Event       string `json:"event"`
+	Timestamp   string `json:"timestamp"`
+	Task        string `json:"task"`
+	Phase       string `json:"phase,omitempty"`
+	Cycle       int    `json:"cycle,omitempty"`
+	Cycles      int    `json:"cycles,omitempty"`
+	DurationMS  int64  `json:"duration_ms,omitempty"`
+	ElapsedMS   int64  `json:"elapsed_ms,omitempty"`
+	RemainingMS int64  `json:"remaining_ms,omitempty"`
+	Shortcut    string `json:"shortcut,omitempty"`
+	Interrupted bool   `json:"interrupted,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// Warnings come from the timer-start/timer-stop envelope.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type timerSummary struct {
+	Summary         bool   `json:"summary"`
+	Task            string `json:"task"`
+	Cycles          int    `json:"cycles"`
+	CyclesCompleted int    `json:"cycles_completed"`
+	FocusMS         int64  `json:"focus_ms"`
+	Interrupted     bool   `json:"interrupted,omitempty"`
+}
+
+func newTimerCmd(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timer",
+		Short: "Run task timers",
+	}
+	cmd.AddCommand(newTimerRunCmd(opts), newTimerStatusCmd(opts), newTimerStopAllCmd(opts), newTimerReportCmd(opts))
+	return cmd
+}
+
+func newTimerRunCmd(opts *rootOptions) *cobra.Command {
+	runOpts := &timerRunOptions{}
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Start a task timer, wait, then stop it (focus sessions)",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if strings.TrimSpace(runOpts.task) == "" {
+				return exitError(ExitCodeUsage, errors.New("missing --task"))
+			}
+			if runOpts.duration <= 0 || runOpts.cycles < 1 || runOpts.breakDur < 0 || runOpts.tick <= 0 {
+				return exitError(ExitCodeUsage, errors.New("--duration and --tick must be positive, --cycles at least 1 and --break not negative"))
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			reporter := newTimerReporter(os.Stdout, runOpts, opts)
+			_, err := runTimer(ctx, opts.actionSession(), runOpts, reporter, opts)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&runOpts.task, "task", "", "Task name")
+	cmd.Flags().DurationVar(&runOpts.duration, "duration", 25*time.Minute, "Focus time per cycle")
+	cmd.Flags().IntVar(&runOpts.cycles, "cycles", 1, "Number of focus sessions")
+	cmd.Flags().DurationVar(&runOpts.breakDur, "break", 5*time.Minute, "Break between cycles (timer stopped)")
+	cmd.Flags().DurationVar(&runOpts.tick, "tick", time.Minute, "Interval between progress events in agent mode")
+	cmd.Flags().StringVar(&runOpts.trace, "trace", "", "Append JSON trace of input/output to a file")
+	return cmd
+}
+
+// runTimer runs the focus/break cycles. When ctx is cancelled during a focus
+// session the timer is still stopped and the elapsed time is reported.
+func runTimer(ctx context.Context, session *actionSession, runOpts *timerRunOptions, reporter *timerReporter, opts *rootOptions) (timerSummary, error) {
+	task := strings.TrimSpace(runOpts.task)
+	summary := timerSummary{Summary: true, Task: task, Cycles: runOpts.cycles}
+	finish := func(err error) (timerSummary, error) {
+		reporter.summary(summary)
+		return summary, err
+	}
+	interrupted := func(elapsed time.Duration) error {
+		summary.Interrupted = true
+		return exitError(ExitCodeInterrupted, fmt.Errorf("interrupted after %s", elapsed.Round(time.Second)))
+	}
+
+	for cycle := 1; cycle <= runOpts.cycles; cycle++ {
+		event := timerEvent{Task: task, Phase: "focus", Cycle: cycle, Cycles: runOpts.cycles}
+		started, err := session.execute(ctx, actionRequest{Action: "timer-start", Task: task, Trace: runOpts.trace}, opts)
+		event.Warnings = started.Warnings
+		if err != nil {
+			event.Event = "error"
+			event.Error = err.Error()
+			reporter.event(event)
+			return finish(err)
+		}
+		event.Event = "start"
+		event.DurationMS = runOpts.duration.Milliseconds()
+		event.Shortcut = started.Shortcut.Name
+		reporter.event(event)
+
+		elapsed, done := waitTimerPhase(ctx, runOpts.duration, reporter.tickEvery(), func(elapsed time.Duration) {
+			reporter.event(timerEvent{Event: "tick", Task: task, Phase: "focus", Cycle: cycle, Cycles: runOpts.cycles,
+				ElapsedMS: elapsed.Milliseconds(), RemainingMS: (runOpts.duration - elapsed).Milliseconds()})
+		})
+		summary.FocusMS += elapsed.Milliseconds()
+
+		// Stop with a fresh context so an interrupt still stops the timer.
+		stopCtx := ctx
+		if !done {
+			stopCtx = context.Background()
+		}
+		stopped, stopErr := session.execute(stopCtx, actionRequest{Action: "timer-stop", Task: task, Trace: runOpts.trace}, opts)
+		stopEvent := timerEvent{Event: "stop", Task: task, Phase: "focus", Cycle: cycle, Cycles: runOpts.cycles,
+			ElapsedMS: elapsed.Milliseconds(), Shortcut: stopped.Shortcut.Name, Interrupted: !done, Warnings: stopped.Warnings}
+		if stopErr != nil {
+			stopEvent.Error = stopErr.Error()
+		}
+		reporter.event(stopEvent)
+		if stopErr != nil {
+			return finish(stopErr)
+		}
+		if !done {
+			return finish(interrupted(elapsed))
+		}
+		summary.CyclesCompleted++
+
+		if cycle == runOpts.cycles || runOpts.breakDur == 0 {
+			continue
+		}
+		reporter.event(timerEvent{Event: "break", Task: task, Phase: "break", Cycle: cycle, Cycles: runOpts.cycles, DurationMS: runOpts.breakDur.Milliseconds()})
+		elapsed, done = waitTimerPhase(ctx, runOpts.breakDur, reporter.tickEvery(), func(elapsed time.Duration) {
+			reporter.event(timerEvent{Event: "tick", Task: task, Phase: "break", Cycle: cycle, Cycles: runOpts.cycles,
+				ElapsedMS: elapsed.Milliseconds(), RemainingMS: (runOpts.breakDur - elapsed).Milliseconds()})
+		})
+		if !done {
+			return finish(interrupted(elapsed))
+		}
+	}
+	return finish(nil)
+}
+
+// waitTimerPhase waits for d, calling onTick every tick. It reports the
+// elapsed time and false when ctx was cancelled first.
+func waitTimerPhase(ctx context.Context, d, tick time.Duration, onTick func(time.Duration)) (time.Duration, bool) {
+	start := timerNow()
+	for {
+		elapsed := timerNow().Sub(start)
+		if elapsed >= d {
+			return d, true
+		}
+		if ctx.Err() != nil {
+			return elapsed, false
+		}
+		wait := d - elapsed
+		if tick > 0 && tick < wait {
+			wait = tick
+		}
+		select {
+		case <-ctx.Done():
+			return timerNow().Sub(start), false
+		case <-timerAfter(wait):
+		}
+		if elapsed := timerNow().Sub(start); elapsed < d && tick > 0 {
+			onTick(elapsed)
+		}
+	}
+}
+
+// timerReporter prints timer progress: NDJSON events in agent mode, a live
+// countdown on a TTY and plain start/stop lines otherwise.
+type timerReporter struct {
+	out io.Writer
+	// errOut receives warnings outside agent mode.
+	errOut io.Writer
+	agent  bool
+	quiet  bool
+	tty    bool
+	tick   time.Duration
+}
+
+func newTimerReporter(out io.Writer, runOpts *timerRunOptions, opts *rootOptions) *timerReporter {
+	return &timerReporter{
+		out:    out,
+		errOut: os.Stderr,
+		agent:  opts.isAgent(),
+		quiet:  opts.noOutput,
+		tty:    !opts.isAgent() && isTerminal(int(os.Stdout.Fd())),
+		tick:   runOpts.tick,
+	}
+}
+
+func (r *timerReporter) tickEvery() time.Duration {
+	switch {
+	case r.agent:
+		return r.tick
+	case r.tty:
+		return time.Second
+	default:
+		return 0
+	}
+}
+
+func (r *timerReporter) event(ev timerEvent) {
+	if r.quiet {
+		return
+	}
+	if r.agent {
+		ev.Timestamp = timerNow().UTC().Format(time.RFC3339Nano)
+		_ = output.PrintJSON(r.out, ev, false)
+		return
+	}
+	progress := fmt.Sprintf("%s %d/%d", ev.Phase, ev.Cycle, ev.Cycles)
+	switch ev.Event {
+	case "start":
+		fmt.Fprintf(r.out, "Started %s timer (%s, %s)\n", ev.Task, progress, time.Duration(ev.DurationMS)*time.Millisecond)
+	case "tick":
+		if r.tty {
+			fmt.Fprintf(r.out, "\r\x1b[K%s  %s  %s left", ev.Task, progress, formatCountdown(time.Duration(ev.RemainingMS)*time.Millisecond))
+		}
+	case "stop":
+		r.clearLine()
+		elapsed := (time.Duration(ev.ElapsedMS) * time.Millisecond).Round(time.Second)
+		switch {
+		case ev.Error != "":
+			fmt.Fprintf(r.out, "Failed to stop %s timer after %s: %s\n", ev.Task, elapsed, ev.Error)
+		case ev.Interrupted:
+			fmt.Fprintf(r.out, "Interrupted: stopped %s timer after %s\n", ev.Task, elapsed)
+		default:
+			fmt.Fprintf(r.out, "Stopped %s timer after %s\n", ev.Task, elapsed)
+		}
+	case "break":
+		fmt.Fprintf(r.out, "Break for %s\n", time.Duration(ev.DurationMS)*time.Millisecond)
+	}
+	for _, warning := range ev.Warnings {
+		fmt.Fprintln(r.errOut, "warning: "+warning)
+	}
+}
+
+func (r *timerReporter) summary(summary timerSummary) {
+	if r.quiet {
+		return
+	}
+	if r.agent {
+		_ = output.PrintJSON(r.out, summary, false)
+		return
+	}
+	r.clearLine()
+	if summary.Cycles > 1 {
+		focus := (time.Duration(summary.FocusMS) * time.Millisecond).Round(time.Second)
+		fmt.Fprintf(r.out, "%s: %d of %d sessions, %s focused\n", summary.Task, summary.CyclesCompleted, summary.Cycles, focus)
+	}
+}
+
+func (r *timerReporter) clearLine() {
+	if r.tty {
+		fmt.Fprint(r.out, "\r\x1b[K")
+	}
+}
+
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
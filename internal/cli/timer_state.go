@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/output"
+	"streaks-cli/internal/timers"
+)
+
+type timerStatusRow struct {
+	Task      string `json:"task"`
+	StartedAt string `json:"started_at"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+	Shortcut  string `json:"shortcut,omitempty"`
+	Stale     bool   `json:"stale,omitempty"`
+}
+
+type timerReportRow struct {
+	Day      string `json:"day"`
+	Task     string `json:"task"`
+	FocusMS  int64  `json:"focus_ms"`
+	Sessions int    `json:"sessions"`
+}
+
+// timerStartWarnings reads the local state before timer-start runs, so a
+// timer that is already running is reported before the shortcut touches it.
+func timerStartWarnings(actionID, task string) []string {
+	if task == "" || actionID != "timer-start" {
+		return nil
+	}
+	state, err := timers.Load()
+	if err != nil {
+		return []string{"timer state read failed: " + err.Error()}
+	}
+	now := timerNow()
+	existing, ok := state.Lookup(task)
+	if !ok || existing.Stale(now, timers.DefaultStaleAfter) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s timer already running for %s", task, formatElapsed(now.Sub(existing.StartedAt)))}
+}
+
+// recordTimerState tracks successful timer-start/timer-stop runs in the local
+// state file. Problems are returned as warnings; they never fail the action.
+func recordTimerState(actionID, task, shortcutName string) []string {
+	if task == "" || (actionID != "timer-start" && actionID != "timer-stop") {
+		return nil
+	}
+	now := timerNow()
+	var warnings []string
+	err := timers.Update(func(state *timers.State) error {
+		if actionID == "timer-stop" {
+			session, ok := state.Stop(task, now, timers.DefaultStaleAfter)
+			switch {
+			case !ok:
+				warnings = append(warnings, fmt.Sprintf("no local start recorded for %s; time was not tracked", task))
+			case session.Stale:
+				warnings = append(warnings, fmt.Sprintf("%s timer was started %s ago; recorded as stale and left out of reports", task, formatElapsed(session.Duration())))
+			}
+			return nil
+		}
+		existing, already := state.Start(task, shortcutName, now)
+		if already {
+			if existing.Stale(now, timers.DefaultStaleAfter) {
+				state.Stop(task, now, timers.DefaultStaleAfter)
+				state.Start(task, shortcutName, now)
+				warnings = append(warnings, fmt.Sprintf("replaced stale %s timer started %s ago", task, formatElapsed(now.Sub(existing.StartedAt))))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		warnings = append(warnings, "timer state write failed: "+err.Error())
+	}
+	return warnings
+}
+
+func newTimerStatusCmd(opts *rootOptions) *cobra.Command {
+	var staleAfter time.Duration
+	var prune bool
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show timers started through the CLI that are still running",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			now := timerNow()
+			var rows []timerStatusRow
+			collect := func(state *timers.State) error {
+				kept := state.Running[:0]
+				for _, running := range state.Running {
+					stale := running.Stale(now, staleAfter)
+					if stale && prune {
+						state.Sessions = append(state.Sessions, timers.Session{Task: running.Task, Start: running.StartedAt, End: now, Stale: true})
+						continue
+					}
+					kept = append(kept, running)
+					rows = append(rows, timerStatusRow{
+						Task:      running.Task,
+						StartedAt: running.StartedAt.UTC().Format(time.RFC3339),
+						ElapsedMS: now.Sub(running.StartedAt).Milliseconds(),
+						Shortcut:  running.Shortcut,
+						Stale:     stale,
+					})
+				}
+				state.Running = kept
+				return nil
+			}
+			// Only --prune writes the state; a plain status is read-only.
+			var err error
+			if prune {
+				err = timers.Update(collect)
+			} else {
+				var state timers.State
+				if state, err = timers.Load(); err == nil {
+					err = collect(&state)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if opts.noOutput {
+				return nil
+			}
+			if opts.isAgent() {
+				for _, row := range rows {
+					if err := output.PrintJSON(os.Stdout, row, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if len(rows) == 0 {
+				fmt.Println("No running timers")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "TASK\tSTARTED\tELAPSED\t")
+			for _, row := range rows {
+				started, _ := time.Parse(time.RFC3339, row.StartedAt)
+				note := ""
+				if row.Stale {
+					note = "stale (stopped outside the CLI?)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.Task, started.Local().Format("Mon 15:04"), formatElapsed(time.Duration(row.ElapsedMS)*time.Millisecond), note)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().DurationVar(&staleAfter, "stale-after", timers.DefaultStaleAfter, "Mark timers running longer than this as stale")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Drop stale timers from the local state")
+	return cmd
+}
+
+func newTimerStopAllCmd(opts *rootOptions) *cobra.Command {
+	var dryRun bool
+	var trace string
+	cmd := &cobra.Command{
+		Use:   "stop-all",
+		Short: "Stop every timer started through the CLI",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			state, err := timers.Load()
+			if err != nil {
+				return err
+			}
+			session := opts.actionSession()
+			summary := actionSummary{Summary: true, Total: len(state.Running)}
+			for _, running := range state.Running {
+				envelope, err := session.execute(context.Background(), actionRequest{Action: "timer-stop", Task: running.Task, DryRun: dryRun, Trace: trace}, opts)
+				if err != nil {
+					summary.Failed++
+				} else {
+					summary.OK++
+				}
+				if opts.noOutput {
+					continue
+				}
+				if opts.isAgent() {
+					if err := output.PrintJSON(os.Stdout, envelope, false); err != nil {
+						return err
+					}
+					continue
+				}
+				switch {
+				case envelope.DryRun:
+					fmt.Printf("%s: would run %s\n", running.Task, envelope.Shortcut.Name)
+				case err != nil:
+					fmt.Printf("%s: failed: %s\n", running.Task, envelope.Error)
+				default:
+					fmt.Printf("%s: stopped after %s\n", running.Task, formatElapsed(timerNow().Sub(running.StartedAt)))
+				}
+				for _, warning := range envelope.Warnings {
+					fmt.Fprintln(os.Stderr, "warning: "+warning)
+				}
+			}
+			if !opts.noOutput {
+				if opts.isAgent() {
+					if err := output.PrintJSON(os.Stdout, summary, false); err != nil {
+						return err
+					}
+				} else if summary.Total == 0 {
+					fmt.Println("No running timers")
+				}
+			}
+			if summary.Failed > 0 {
+				return exitError(ExitCodeActionFailed, fmt.Errorf("%d of %d timers failed to stop", summary.Failed, summary.Total))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which timers would be stopped")
+	cmd.Flags().StringVar(&trace, "trace", "", "Append JSON trace of input/output to a file")
+	return cmd
+}
+
+func newTimerReportCmd(opts *rootOptions) *cobra.Command {
+	var since, until string
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Total focus time per task per day from the local timer state",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			now := timerNow()
+			from, err := parseTimeBound(since, now, false)
+			if err != nil {
+				return exitError(ExitCodeUsage, err)
+			}
+			to := now
+			if until != "" {
+				if to, err = parseTimeBound(until, now, true); err != nil {
+					return exitError(ExitCodeUsage, err)
+				}
+			}
+			state, err := timers.Load()
+			if err != nil {
+				return err
+			}
+			rows := buildTimerReport(state, from, to, now)
+			if opts.noOutput {
+				return nil
+			}
+			if opts.isAgent() {
+				for _, row := range rows {
+					if err := output.PrintJSON(os.Stdout, row, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			return printTimerReport(rows)
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "7d", "Start of the report (e.g. 7d, 2026-01-31, today)")
+	cmd.Flags().StringVar(&until, "until", "", "End of the report (dates include the whole day; default now)")
+	return cmd
+}
+
+// buildTimerReport includes timers that are still running (and not stale) up
+// to now.
+func buildTimerReport(state timers.State, from, to, now time.Time) []timerReportRow {
+	sessions := append([]timers.Session{}, state.Sessions...)
+	for _, running := range state.Running {
+		if !running.Stale(now, timers.DefaultStaleAfter) {
+			sessions = append(sessions, timers.Session{Task: running.Task, Start: running.StartedAt, End: now})
+		}
+	}
+	totals := timers.Report(sessions, from, to, now.Location())
+	rows := make([]timerReportRow, 0, len(totals))
+	for _, total := range totals {
+		rows = append(rows, timerReportRow{Day: total.Day, Task: total.Task, FocusMS: total.Duration.Milliseconds(), Sessions: total.Sessions})
+	}
+	return rows
+}
+
+func printTimerReport(rows []timerReportRow) error {
+	if len(rows) == 0 {
+		fmt.Println("No tracked timer sessions")
+		return nil
+	}
+	byTask := map[string]time.Duration{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tTASK\tFOCUS\tSESSIONS")
+	for _, row := range rows {
+		focus := time.Duration(row.FocusMS) * time.Millisecond
+		byTask[row.Task] += focus
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", row.Day, row.Task, formatElapsed(focus), row.Sessions)
+	}
+	tasks := make([]string, 0, len(byTask))
+	for task := range byTask {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+	fmt.Fprintln(w, "\t\t\t")
+	for _, task := range tasks {
+		fmt.Fprintf(w, "total\t%s\t%s\t\n", task, formatElapsed(byTask[task]))
+	}
+	return w.Flush()
+}
+
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+	"streaks-cli/internal/timers"
+)
+
+// fakeTimerClock makes timerAfter advance a fake clock instantly.
+func fakeTimerClock(t *testing.T, onWait func(time.Duration)) {
+	t.Helper()
+	origNow, origAfter := timerNow, timerAfter
+	t.Cleanup(func() { timerNow, timerAfter = origNow, origAfter })
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	timerNow = func() time.Time { return now }
+	timerAfter = func(d time.Duration) <-chan time.Time {
+		now = now.Add(d)
+		if onWait != nil {
+			onWait(d)
+		}
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+}
+
+func stubTimerShortcuts(t *testing.T) *[]string {
+	t.Helper()
+	origRun := runShortcut
+	t.Cleanup(func() { runShortcut = origRun })
+	dir := t.TempDir()
+	t.Setenv(config.EnvConfigPath, filepath.Join(dir, "config.json"))
+	t.Setenv(timers.EnvTimersPath, filepath.Join(dir, "timers.json"))
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{
+		"timer-start": {Name: "Start Timer"},
+		"timer-stop":  {Name: "Stop Timer"},
+	}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var ran []string
+	runShortcut = func(_ context.Context, name string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name)
+		return []byte("ok"), nil
+	}
+	return &ran
+}
+
+func TestRunTimerCycles(t *testing.T) {
+	ran := stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+
+	var out bytes.Buffer
+	opts := &rootOptions{agent: true}
+	runOpts := &timerRunOptions{task: "Read", duration: 25 * time.Minute, breakDur: 5 * time.Minute, cycles: 2, tick: 10 * time.Minute}
+	summary, err := runTimer(context.Background(), newActionSession(), runOpts, newTimerReporter(&out, runOpts, opts), opts)
+	if err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if strings.Join(*ran, ",") != "Start Timer,Stop Timer,Start Timer,Stop Timer" {
+		t.Fatalf("unexpected runs: %v", *ran)
+	}
+	if summary.CyclesCompleted != 2 || summary.FocusMS != (50*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("invalid NDJSON %q: %v", line, err)
+		}
+		if name, ok := ev["event"].(string); ok {
+			events = append(events, name+":"+ev["phase"].(string))
+		}
+	}
+	want := "start:focus,tick:focus,tick:focus,stop:focus,break:break,start:focus,tick:focus,tick:focus,stop:focus"
+	if strings.Join(events, ",") != want {
+		t.Fatalf("events = %s, want %s", strings.Join(events, ","), want)
+	}
+}
+
+func TestRunTimerWarnings(t *testing.T) {
+	stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+	reset := func() {
+		t.Helper()
+		if err := timers.Save(timers.State{Running: []timers.Running{{Task: "Read", StartedAt: timerNow().Add(-10 * time.Minute)}}}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	runOpts := &timerRunOptions{task: "Read", duration: 25 * time.Minute, cycles: 1, tick: time.Hour}
+
+	reset()
+	var out bytes.Buffer
+	opts := &rootOptions{agent: true}
+	if _, err := runTimer(context.Background(), newActionSession(), runOpts, newTimerReporter(&out, runOpts, opts), opts); err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	var start timerEvent
+	if err := json.Unmarshal([]byte(strings.SplitN(out.String(), "\n", 2)[0]), &start); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if start.Event != "start" || len(start.Warnings) != 1 || !strings.Contains(start.Warnings[0], "already running") {
+		t.Fatalf("start event should carry the already-running warning: %+v", start)
+	}
+
+	reset()
+	var human, errOut bytes.Buffer
+	opts = &rootOptions{}
+	reporter := newTimerReporter(&human, runOpts, opts)
+	reporter.errOut = &errOut
+	if _, err := runTimer(context.Background(), newActionSession(), runOpts, reporter, opts); err != nil {
+		t.Fatalf("runTimer: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "warning: Read timer already running for 10m") {
+		t.Fatalf("human mode should print the warning, got %q", errOut.String())
+	}
+}
+
+func TestRunTimerInterrupted(t *testing.T) {
+	ran := stubTimerShortcuts(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	waits := 0
+	fakeTimerClock(t, func(time.Duration) {
+		waits++
+		if waits == 2 {
+			cancel()
+		}
+	})
+
+	opts := &rootOptions{noOutput: true}
+	runOpts := &timerRunOptions{task: "Read", duration: 25 * time.Minute, cycles: 1, tick: time.Minute}
+	reporter := newTimerReporter(&bytes.Buffer{}, runOpts, opts)
+	reporter.tick = time.Minute
+	reporter.agent = true
+	summary, err := runTimer(ctx, newActionSession(), runOpts, reporter, opts)
+	if code, _ := exitCodeFromError(err); code != ExitCodeInterrupted {
+		t.Fatalf("expected interrupted exit, got %v", err)
+	}
+	if strings.Join(*ran, ",") != "Start Timer,Stop Timer" || !summary.Interrupted || summary.CyclesCompleted != 0 {
+		t.Fatalf("interrupted run should still stop the timer: ran=%v summary=%+v", *ran, summary)
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	if got := formatCountdown(24*time.Minute + 5*time.Second); got != "24:05" {
+		t.Fatalf("got %s", got)
+	}
+	if got := formatCountdown(90 * time.Minute); got != "1:30:00" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestRecordTimerStateWarnings(t *testing.T) {
+	stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+
+	if warnings := timerStartWarnings("timer-start", "Read"); len(warnings) != 0 {
+		t.Fatalf("unexpected warnings before the first start: %v", warnings)
+	}
+	if warnings := recordTimerState("timer-start", "Read", "Start Timer"); len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	warnings := timerStartWarnings("timer-start", "read")
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "already running") {
+		t.Fatalf("expected already running warning, got %v", warnings)
+	}
+	if warnings := recordTimerState("timer-start", "read", "Start Timer"); len(warnings) != 0 {
+		t.Fatalf("recording a second start should not warn again: %v", warnings)
+	}
+	if warnings := recordTimerState("timer-stop", "Read", "Stop Timer"); len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	warnings = recordTimerState("timer-stop", "Read", "Stop Timer")
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "no local start") {
+		t.Fatalf("expected missing start warning, got %v", warnings)
+	}
+	if warnings := recordTimerState("task-complete", "Read", "Complete"); warnings != nil {
+		t.Fatalf("non-timer actions should not be tracked: %v", warnings)
+	}
+
+	state, err := timers.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(state.Running) != 0 || len(state.Sessions) != 1 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestTimerStartWarnsBeforeRunning(t *testing.T) {
+	stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+	if err := timers.Save(timers.State{Running: []timers.Running{{Task: "Read", StartedAt: timerNow().Add(-10 * time.Minute)}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	var warned []string
+	runShortcut = func(_ context.Context, _ string, _ []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		// The warning must come from the state as it was before the run.
+		warned = timerStartWarnings("timer-start", "Read")
+		if err := timers.Save(timers.State{}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		return []byte("ok"), nil
+	}
+	envelope, err := newActionSession().execute(context.Background(), actionRequest{Action: "timer-start", Task: "Read"}, &rootOptions{agent: true})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(warned) != 1 || len(envelope.Warnings) != 1 || !strings.Contains(envelope.Warnings[0], "already running for 10m") {
+		t.Fatalf("expected the pre-run warning, got %v", envelope.Warnings)
+	}
+}
+
+func TestTimerStatusReadOnly(t *testing.T) {
+	stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+	path, err := timers.Path()
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"timer", "status", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("status without --prune should not write the state file: %v", err)
+	}
+
+	stale := timers.Running{Task: "Walk", StartedAt: timerNow().Add(-20 * time.Hour)}
+	if err := timers.Save(timers.State{Running: []timers.Running{stale}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	before, _ := os.ReadFile(path)
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"timer", "status", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Fatalf("status without --prune rewrote the state file")
+	}
+
+	cmd = newRootCmd()
+	cmd.SetArgs([]string{"timer", "status", "--prune", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("status --prune: %v", err)
+	}
+	state, err := timers.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(state.Running) != 0 || len(state.Sessions) != 1 || !state.Sessions[0].Stale {
+		t.Fatalf("--prune should move the stale timer to sessions: %+v", state)
+	}
+}
+
+func TestTimerStopAll(t *testing.T) {
+	ran := stubTimerShortcuts(t)
+	fakeTimerClock(t, nil)
+	start := timerNow()
+	if err := timers.Save(timers.State{Running: []timers.Running{
+		{Task: "Read", StartedAt: start.Add(-20 * time.Minute)},
+		{Task: "Walk", StartedAt: start.Add(-5 * time.Minute)},
+	}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"timer", "stop-all", "--no-output"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("stop-all: %v", err)
+	}
+	if strings.Join(*ran, ",") != "Stop Timer,Stop Timer" {
+		t.Fatalf("unexpected runs: %v", *ran)
+	}
+	state, err := timers.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(state.Running) != 0 || len(state.Sessions) != 2 {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestBuildTimerReport(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	state := timers.State{
+		Running: []timers.Running{
+			{Task: "Read", StartedAt: now.Add(-30 * time.Minute)},
+			{Task: "Walk", StartedAt: now.Add(-20 * time.Hour)},
+		},
+		Sessions: []timers.Session{
+			{Task: "Read", Start: time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 0, 15, 0, 0, time.UTC)},
+			{Task: "Read", Start: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)},
+		},
+	}
+	rows := buildTimerReport(state, now.AddDate(0, 0, -7), now, now)
+	want := []timerReportRow{
+		{Day: "2026-03-01", Task: "Read", FocusMS: (30 * time.Minute).Milliseconds(), Sessions: 1},
+		{Day: "2026-03-02", Task: "Read", FocusMS: (45 * time.Minute).Milliseconds(), Sessions: 2},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v", rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Fatalf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"streaks-cli/internal/output"
+)
+
+type todayRow struct {
+	Task          string `json:"task"`
+	Status        string `json:"status"`
+	CurrentStreak *int   `json:"current_streak,omitempty"`
+	Timer         string `json:"timer,omitempty"`
+	Paused        bool   `json:"paused,omitempty"`
+	Shortcut      string `json:"shortcut,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ErrorCode     string `json:"error_code,omitempty"`
+}
+
+type todayOptions struct {
+	pending bool
+	done    bool
+}
+
+func newTodayCmd(opts *rootOptions) *cobra.Command {
+	todayOpts := &todayOptions{}
+	cmd := &cobra.Command{
+		Use:   "today",
+		Short: "Show today's status for every task",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			rows, err := buildToday(context.Background(), opts.actionSession(), opts)
+			if err != nil {
+				return err
+			}
+			rows = filterTodayRows(rows, todayOpts)
+			if opts.noOutput {
+				return nil
+			}
+			if opts.isAgent() {
+				for _, row := range rows {
+					if err := output.PrintJSON(os.Stdout, row, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			return printTodayTable(rows)
+		},
+	}
+	cmd.Flags().BoolVar(&todayOpts.pending, "pending", false, "Only show pending tasks")
+	cmd.Flags().BoolVar(&todayOpts.done, "done", false, "Only show completed tasks")
+	return cmd
+}
+
+func buildToday(ctx context.Context, session *actionSession, opts *rootOptions) ([]todayRow, error) {
+	list, err := session.invoke(ctx, "task-list", "", "", opts)
+	if err != nil {
+		return nil, err
+	}
+	tasks := parseTaskList(list.Result.Output)
+	rows := make([]todayRow, 0, len(tasks))
+	for _, task := range tasks {
+		rows = append(rows, todayStatusRow(ctx, session, task, opts))
+	}
+	return rows, nil
+}
+
+func todayStatusRow(ctx context.Context, session *actionSession, task string, opts *rootOptions) todayRow {
+	row := todayRow{Task: task, Status: taskStateUnknown}
+	inv, err := session.invoke(ctx, "task-status", task, "", opts)
+	row.Shortcut = inv.Shortcut
+	if err != nil {
+		row.Error = err.Error()
+		if code, _ := exitCodeFromError(err); code != 0 {
+			row.ErrorCode = errorCodeLabel(code)
+		}
+		return row
+	}
+	status := parseTaskStatus(inv.Result.Output)
+	row.Status = status.State
+	row.CurrentStreak = status.CurrentStreak
+	row.Paused = status.Paused
+	if status.TimerRunning != nil {
+		row.Timer = "stopped"
+		if *status.TimerRunning {
+			row.Timer = "running"
+		}
+	}
+	return row
+}
+
+func filterTodayRows(rows []todayRow, todayOpts *todayOptions) []todayRow {
+	if !todayOpts.pending && !todayOpts.done {
+		return rows
+	}
+	out := make([]todayRow, 0, len(rows))
+	for _, row := range rows {
+		if (todayOpts.pending && row.Status == taskStatePending) || (todayOpts.done && row.Status == taskStateDone) {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+func printTodayTable(rows []todayRow) error {
+	if len(rows) == 0 {
+		fmt.Println("No tasks")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSTATUS\tSTREAK\tTIMER")
+	for _, row := range rows {
+		status := row.Status
+		if row.ErrorCode == "shortcut_missing" {
+			status = "missing shortcut"
+		} else if row.Error != "" {
+			status = "error: " + row.Error
+		}
+		streak := "-"
+		if row.CurrentStreak != nil {
+			streak = strconv.Itoa(*row.CurrentStreak)
+		}
+		timer := row.Timer
+		if timer == "" {
+			timer = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.Task, status, streak, timer)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"streaks-cli/internal/discovery"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestParseTaskStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		state  string
+		streak int
+		timer  *bool
+	}{
+		{"json", `{"title":"Read","is_complete":"Yes","current_streak":"12","timer_is_timing":"No"}`, taskStateDone, 12, boolPtr(false)},
+		{"json missed", `{"is_complete":false,"is_missed":true}`, taskStateMissed, -1, nil},
+		{"json today status", `{"today_status":"Incomplete","current_streak":3}`, taskStatePending, 3, nil},
+		{"key value text", "Title: Read\nIs Complete: No\nCurrent Streak: 4\nTimer Is Timing: Yes", taskStatePending, 4, boolPtr(true)},
+		{"bare status", "Done\n", taskStateDone, -1, nil},
+		{"bare pending", "Not complete", taskStatePending, -1, nil},
+		{"sentence", "Read is complete", taskStateUnknown, -1, nil},
+		{"sentence mission", "Mission is due", taskStateUnknown, -1, nil},
+		{"empty", "", taskStateUnknown, -1, nil},
+		{"title mission", `{"title":"Mission","today_status":"Complete"}`, taskStateDone, -1, nil},
+		{"title residue", "Title: Clean residue\nStatus: Done", taskStateDone, -1, nil},
+		{"title dojo", `{"title":"Go to dojo","is_complete":false}`, taskStatePending, -1, nil},
+		{"title only", `{"title":"Mission residue to do"}`, taskStateUnknown, -1, nil},
+		{"status words", `{"status":"Missed"}`, taskStateMissed, -1, nil},
+	}
+	for _, tc := range cases {
+		got := parseTaskStatus([]byte(tc.output))
+		if got.State != tc.state {
+			t.Fatalf("%s: expected state %s, got %s", tc.name, tc.state, got.State)
+		}
+		if tc.streak >= 0 && (got.CurrentStreak == nil || *got.CurrentStreak != tc.streak) {
+			t.Fatalf("%s: expected streak %d, got %v", tc.name, tc.streak, got.CurrentStreak)
+		}
+		if tc.timer != nil && (got.TimerRunning == nil || *got.TimerRunning != *tc.timer) {
+			t.Fatalf("%s: expected timer %v, got %v", tc.name, *tc.timer, got.TimerRunning)
+		}
+	}
+}
+
+func TestStateFromTextWholeWords(t *testing.T) {
+	cases := map[string]string{
+		"Mission":           taskStateUnknown,
+		"residue":           taskStateUnknown,
+		"Go to dojo":        taskStateUnknown,
+		"Missed":            taskStateMissed,
+		"Due today":         taskStatePending,
+		"To-do":             taskStatePending,
+		"Not completed yet": taskStatePending,
+		"Completed":         taskStateDone,
+	}
+	for text, want := range cases {
+		if got := stateFromText(text); got != want {
+			t.Fatalf("%q: expected %s, got %s", text, want, got)
+		}
+	}
+}
+
+func TestParseTaskList(t *testing.T) {
+	cases := map[string][]string{
+		"Read\nRun\n\nRead\n":                        {"Read", "Run"},
+		`["Read","Run"]`:                             {"Read", "Run"},
+		`[{"title":"Read"},{"title":"Run"}]`:         {"Read", "Run"},
+		`{"tasks":[{"name":"Read"},{"name":"Run"}]}`: {"Read", "Run"},
+		"- Read\n• Run":                              {"Read", "Run"},
+	}
+	for input, want := range cases {
+		got := parseTaskList([]byte(input))
+		if len(got) != len(want) {
+			t.Fatalf("%q: expected %v, got %v", input, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%q: expected %v, got %v", input, want, got)
+			}
+		}
+	}
+}
+
+func TestBuildTodayReportsMissingShortcutPerRow(t *testing.T) {
+	origRun := runShortcut
+	origDiscover := discover
+	origList := listShortcuts
+	defer func() {
+		runShortcut = origRun
+		discover = origDiscover
+		listShortcuts = origList
+	}()
+
+	discoverCalls := 0
+	discover = func(_ context.Context) (discovery.Discovery, error) {
+		discoverCalls++
+		return discovery.Discovery{App: discovery.AppInfo{Name: "Streaks"}}, nil
+	}
+	listShortcuts = func(_ context.Context, _ string) ([]shortcuts.Shortcut, error) {
+		return []shortcuts.Shortcut{{Name: "Task List"}}, nil
+	}
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		switch {
+		case name == "Task List":
+			return []byte("Read\nRun\n"), nil
+		case name == "Get Task" && string(input) == `{"task":"Read"}`:
+			return []byte(`{"is_complete":"Yes","current_streak":"7"}`), nil
+		default:
+			return nil, errors.New("Couldn't find shortcut " + name)
+		}
+	}
+
+	t.Setenv("STREAKS_CLI_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	rows, err := buildToday(context.Background(), newActionSession(), &rootOptions{})
+	if err != nil {
+		t.Fatalf("buildToday: %v", err)
+	}
+	if discoverCalls != 1 {
+		t.Fatalf("expected discovery once, got %d", discoverCalls)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+	if rows[0].Status != taskStateDone || rows[0].CurrentStreak == nil || *rows[0].CurrentStreak != 7 {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].ErrorCode != "shortcut_missing" || rows[1].Status != taskStateUnknown {
+		t.Fatalf("expected missing shortcut row, got %+v", rows[1])
+	}
+
+	pending := filterTodayRows(rows, &todayOptions{done: true})
+	if len(pending) != 1 || pending[0].Task != "Read" {
+		t.Fatalf("unexpected filtered rows: %+v", pending)
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -10,8 +10,10 @@ type traceEntry struct {
 	Timestamp string          `json:"timestamp"`
 	Shortcut  string          `json:"shortcut"`
 	Input     json.RawMessage `json:"input,omitempty"`
-	Output    json.RawMessage `json:"output,omitempty"`
-	Error     string          `json:"error,omitempty"`
+	// InputFiles are the --input-file paths passed after Input.
+	InputFiles []string        `json:"input_files,omitempty"`
+	Output     json.RawMessage `json:"output,omitempty"`
+	Error      string          `json:"error,omitempty"`
 }
 
 func appendTrace(path string, entry traceEntry) error {
@@ -26,6 +28,8 @@ func appendTrace(path string, entry traceEntry) error {
 	if entry.Timestamp == "" {
 		entry.Timestamp = time.Now().UTC().Format(time.RFC3339)
 	}
+	entry.Input = rawJSON(entry.Input)
+	entry.Output = rawJSON(entry.Output)
 	data, err := json.Marshal(entry)
 	if err != nil {
 		return err
@@ -33,3 +37,13 @@ func appendTrace(path string, entry traceEntry) error {
 	_, err = f.Write(append(data, '\n'))
 	return err
 }
+
+// rawJSON keeps valid JSON as is and quotes anything else, such as the plain
+// text produced by a text input adapter.
+func rawJSON(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 || json.Valid(data) {
+		return data
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
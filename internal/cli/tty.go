@@ -1,11 +0,0 @@
-package cli
-
-import "os"
-
-func isTTY(file *os.File) bool {
-	info, err := file.Stat()
-	if err != nil {
-		return false
-	}
-	return (info.Mode() & os.ModeCharDevice) != 0
-}
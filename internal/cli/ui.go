@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"streaks-cli/internal/tui"
+)
+
+var isTerminal = term.IsTerminal
+
+func newUICmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ui",
+		Short: "Full-screen interactive task list",
+		Long: "Full-screen interactive task list.\n\n" +
+			"Keys: c/enter complete, m miss, t toggle timer, s/x start/stop timer, r refresh, j/k or arrows move, q quit.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if opts.isAgent() {
+				return exitError(ExitCodeUsage, fmt.Errorf("st ui is interactive and not available in --agent mode; use st today"))
+			}
+			inFd, outFd := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+			if !isTerminal(inFd) || !isTerminal(outFd) {
+				return exitError(ExitCodeUsage, fmt.Errorf("st ui requires a terminal; use st today for scripts"))
+			}
+			state, err := term.MakeRaw(inFd)
+			if err != nil {
+				return err
+			}
+			defer term.Restore(inFd, state)
+			return tui.Run(context.Background(), newUIRunner(opts.actionSession(), opts), os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// uiRunner adapts the action session to the TUI. The session is not safe for
+// concurrent use, so calls are serialized; they still run off the UI loop.
+type uiRunner struct {
+	mu      sync.Mutex
+	session *actionSession
+	opts    *rootOptions
+}
+
+func newUIRunner(session *actionSession, opts *rootOptions) *uiRunner {
+	quiet := *opts
+	quiet.noOutput = true
+	quiet.verbose = false
+	return &uiRunner{session: session, opts: &quiet}
+}
+
+func (r *uiRunner) Rows(ctx context.Context) ([]tui.Row, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	today, err := buildToday(ctx, r.session, r.opts)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]tui.Row, 0, len(today))
+	for _, row := range today {
+		rows = append(rows, tui.Row{
+			Task:   row.Task,
+			Status: row.Status,
+			Streak: row.CurrentStreak,
+			Timer:  row.Timer,
+			Error:  row.Error,
+		})
+	}
+	return rows, nil
+}
+
+func (r *uiRunner) Run(ctx context.Context, action, task string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.session.execute(ctx, actionRequest{Action: action, Task: task}, r.opts)
+	return err
+}
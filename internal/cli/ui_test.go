@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"streaks-cli/internal/config"
+	"streaks-cli/internal/shortcuts"
+)
+
+func TestUIRefusesAgentAndNonTTY(t *testing.T) {
+	origIsTerminal := isTerminal
+	defer func() { isTerminal = origIsTerminal }()
+
+	isTerminal = func(int) bool { return false }
+	for _, args := range [][]string{{"ui", "--agent"}, {"ui"}} {
+		cmd := newRootCmd()
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		if code, _ := exitCodeFromError(err); code != ExitCodeUsage {
+			t.Fatalf("%v: expected usage error, got %v", args, err)
+		}
+	}
+}
+
+func TestUIRunner(t *testing.T) {
+	origRun := runShortcut
+	defer func() { runShortcut = origRun }()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.json"))
+	if _, err := config.Write(config.Config{Mappings: map[string]config.ShortcutRef{
+		"task-list":     {Name: "List Tasks"},
+		"task-status":   {Name: "Task Status"},
+		"task-complete": {Name: "Complete Task"},
+	}}); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var ran []string
+	runShortcut = func(_ context.Context, name string, input []byte, _ shortcuts.RunOptions) ([]byte, error) {
+		ran = append(ran, name+" "+string(input))
+		switch name {
+		case "List Tasks":
+			return []byte("Read\n"), nil
+		case "Task Status":
+			return []byte(`{"status":"pending","current_streak":3}`), nil
+		}
+		return nil, errors.New("shortcut failed")
+	}
+
+	runner := newUIRunner(newActionSession(), &rootOptions{})
+	rows, err := runner.Rows(context.Background())
+	if err != nil || len(rows) != 1 || rows[0].Status != "pending" || rows[0].Streak == nil || *rows[0].Streak != 3 {
+		t.Fatalf("unexpected rows %+v (%v)", rows, err)
+	}
+	if err := runner.Run(context.Background(), "task-complete", "Read"); err == nil || !strings.Contains(err.Error(), "shortcut failed") {
+		t.Fatalf("expected action error, got %v", err)
+	}
+	if ran[len(ran)-1] != `Complete Task {"task":"Read"}` {
+		t.Fatalf("unexpected runs: %v", ran)
+	}
+}
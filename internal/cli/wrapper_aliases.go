@@ -1,5 +1,9 @@
 package cli
 
+import "streaks-cli/internal/discovery"
+
+const originWrapper = "wrapper"
+
 var wrapperShortcutNames = map[string][]string{
 	"task-complete": {"Complete Task"},
 	"task-miss":     {"Mark Task Missed"},
@@ -29,3 +33,18 @@ func addWrapperCandidates(actionID string, candidates []string) []string {
 	}
 	return candidates
 }
+
+// addWrapperCandidateDetails is addWrapperCandidates keeping candidate origins.
+func addWrapperCandidateDetails(actionID string, candidates []discovery.ShortcutCandidate) []discovery.ShortcutCandidate {
+	seen := make(map[string]struct{}, len(candidates))
+	for _, cand := range candidates {
+		seen[cand.Name] = struct{}{}
+	}
+	for _, wrapper := range wrapperShortcutNames[actionID] {
+		if _, ok := seen[wrapper]; ok {
+			continue
+		}
+		candidates = append(candidates, discovery.ShortcutCandidate{Name: wrapper, Origin: originWrapper})
+	}
+	return candidates
+}
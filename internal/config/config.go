@@ -3,8 +3,10 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -13,14 +15,120 @@ const (
 	EnvConfigPath         = "STREAKS_CLI_CONFIG"
 )
 
+// Resolution strategies for Config.Prefer and --strategy.
+const (
+	StrategyAuto          = "auto"
+	StrategyWrappersFirst = "wrappers-first"
+	StrategyIntentsFirst  = "intents-first"
+	StrategyMappingOnly   = "mapping-only"
+
+	// legacyStrategyShim is the old name for wrappers-first.
+	legacyStrategyShim = "shim"
+)
+
+// Strategies lists the valid resolution strategies.
+func Strategies() []string {
+	return []string{StrategyAuto, StrategyWrappersFirst, StrategyIntentsFirst, StrategyMappingOnly}
+}
+
+// ParseStrategy validates a strategy name; empty means auto.
+func ParseStrategy(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		return StrategyAuto, nil
+	case legacyStrategyShim:
+		return StrategyWrappersFirst, nil
+	}
+	for _, strategy := range Strategies() {
+		if value == strategy {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown strategy %q (expected %s)", value, strings.Join(Strategies(), ", "))
+}
+
 type ShortcutRef struct {
 	Name string `json:"name,omitempty"`
 	ID   string `json:"id,omitempty"`
+	// Input reshapes the action payload for this shortcut (default json).
+	Input *InputAdapter `json:"input,omitempty"`
+	// Output is the output type (UTI) requested from this shortcut, and
+	// Extract turns its raw output into the envelope result.
+	Output  string     `json:"output,omitempty"`
+	Extract *Extractor `json:"extract,omitempty"`
+	// Fallbacks are tried in order when this shortcut is not in the library.
+	Fallbacks []ShortcutRef `json:"fallbacks,omitempty"`
+}
+
+type RoutineStep struct {
+	Action          string          `json:"action"`
+	Task            string          `json:"task,omitempty"`
+	Status          string          `json:"status,omitempty"`
+	Input           json.RawMessage `json:"input,omitempty"`
+	Shortcut        string          `json:"shortcut,omitempty"`
+	Note            string          `json:"note,omitempty"`
+	IfPending       bool            `json:"if_pending,omitempty"`
+	UnlessDone      bool            `json:"unless_done,omitempty"`
+	ContinueOnError bool            `json:"continue_on_error,omitempty"`
+}
+
+type Routine struct {
+	Description string        `json:"description,omitempty"`
+	Steps       []RoutineStep `json:"steps"`
 }
 
 type Config struct {
 	Mappings map[string]ShortcutRef `json:"mappings,omitempty"`
-	Prefer   string                 `json:"prefer,omitempty"` // "shim" or "auto"
+	// TaskMappings are keyed by action ID, then task name. They take
+	// precedence over Mappings for that task.
+	TaskMappings map[string]map[string]ShortcutRef `json:"task_mappings,omitempty"`
+	Prefer       string                            `json:"prefer,omitempty"` // see Strategies
+	// ShortcutsFolder limits shortcut lookups to one Shortcuts folder.
+	ShortcutsFolder string             `json:"shortcuts_folder,omitempty"`
+	Routines        map[string]Routine `json:"routines,omitempty"`
+}
+
+// TaskMapping returns the mapping for an action and task, matching the task
+// name case-insensitively. The stored task name is returned with the ref.
+func (c Config) TaskMapping(actionID, task string) (string, ShortcutRef, bool) {
+	task = strings.TrimSpace(task)
+	if task == "" {
+		return "", ShortcutRef{}, false
+	}
+	for name, ref := range c.TaskMappings[actionID] {
+		if strings.EqualFold(name, task) {
+			return name, ref, true
+		}
+	}
+	return "", ShortcutRef{}, false
+}
+
+// SetTaskMapping stores ref for an action and task, replacing an existing
+// entry whose task differs only in case.
+func (c *Config) SetTaskMapping(actionID, task string, ref ShortcutRef) {
+	c.RemoveTaskMapping(actionID, task)
+	if c.TaskMappings == nil {
+		c.TaskMappings = make(map[string]map[string]ShortcutRef)
+	}
+	if c.TaskMappings[actionID] == nil {
+		c.TaskMappings[actionID] = make(map[string]ShortcutRef)
+	}
+	c.TaskMappings[actionID][strings.TrimSpace(task)] = ref
+}
+
+// RemoveTaskMapping deletes the mapping for an action and task and reports
+// whether one existed.
+func (c *Config) RemoveTaskMapping(actionID, task string) bool {
+	name, _, ok := c.TaskMapping(actionID, task)
+	if !ok {
+		return false
+	}
+	delete(c.TaskMappings[actionID], name)
+	if len(c.TaskMappings[actionID]) == 0 {
+		delete(c.TaskMappings, actionID)
+	}
+	return true
 }
 
 func DefaultConfig() Config {
@@ -40,6 +148,14 @@ func Path() (string, error) {
 	return filepath.Join(home, ".config", DefaultConfigDirName, DefaultConfigFileName), nil
 }
 
+func Dir() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
 func Load() (Config, bool, error) {
 	path, err := Path()
 	if err != nil {
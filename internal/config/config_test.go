@@ -1,7 +1,9 @@
 package config
 
 import (
+	"encoding/json"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +60,161 @@ func TestConfigWriteAndLoad(t *testing.T) {
 		t.Fatalf("unexpected mapping: %v", loaded.Mappings)
 	}
 }
+
+func TestParseStrategy(t *testing.T) {
+	cases := map[string]string{
+		"":               StrategyAuto,
+		"Auto":           StrategyAuto,
+		"shim":           StrategyWrappersFirst,
+		"intents-first":  StrategyIntentsFirst,
+		" mapping-only ": StrategyMappingOnly,
+	}
+	for in, want := range cases {
+		got, err := ParseStrategy(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseStrategy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseStrategy("random"); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
+
+func TestTaskMappings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetTaskMapping("task-complete", "Run 5k", ShortcutRef{Name: "Log Run"})
+	cfg.SetTaskMapping("task-complete", "run 5K", ShortcutRef{Name: "Log Run v2"})
+	name, ref, ok := cfg.TaskMapping("task-complete", "RUN 5k")
+	if !ok || name != "run 5K" || ref.Name != "Log Run v2" || len(cfg.TaskMappings["task-complete"]) != 1 {
+		t.Fatalf("unexpected task mapping: %q %+v %v (%v)", name, ref, ok, cfg.TaskMappings)
+	}
+	if _, _, ok := cfg.TaskMapping("task-miss", "Run 5k"); ok {
+		t.Fatalf("task mappings are scoped to their action")
+	}
+	if !cfg.RemoveTaskMapping("task-complete", "Run 5k") || cfg.TaskMappings != nil && len(cfg.TaskMappings) != 0 {
+		t.Fatalf("expected mapping removed, got %v", cfg.TaskMappings)
+	}
+	if cfg.RemoveTaskMapping("task-complete", "Run 5k") {
+		t.Fatalf("second remove should report false")
+	}
+}
+
+func TestInputAdapters(t *testing.T) {
+	payload := []byte(`{"task":"Read","status":"done"}`)
+	cases := []struct {
+		spec string
+		want string
+	}{
+		{"json", `{"task":"Read","status":"done"}`},
+		{"text:{{.task}}", "Read"},
+		{"text:{{.task}} is {{.status}}", "Read is done"},
+		{"rename:task=name", `{"name":"Read","status":"done"}`},
+	}
+	for _, tc := range cases {
+		adapter, err := ParseInputAdapter(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		got, err := adapter.Apply(payload)
+		if err != nil || string(got) != tc.want {
+			t.Fatalf("%s: got %s (%v), want %s", tc.spec, got, err, tc.want)
+		}
+	}
+	if _, err := ParseInputAdapter("xml"); err == nil {
+		t.Fatalf("expected unknown adapter error")
+	}
+	if _, err := ParseInputAdapter("rename:task"); err == nil {
+		t.Fatalf("expected invalid rename error")
+	}
+	for _, spec := range []string{"rename:task=name,title=name", "rename:task=name,task=title"} {
+		if _, err := ParseInputAdapter(spec); err == nil {
+			t.Fatalf("%s: expected duplicate rename error", spec)
+		}
+	}
+	var dup InputAdapter
+	if err := json.Unmarshal([]byte(`{"task":"name","title":"name"}`), &dup); err == nil || !strings.Contains(err.Error(), "both renamed") {
+		t.Fatalf("expected duplicate target error from config, got %v", err)
+	}
+	swap, _ := ParseInputAdapter("rename:task=name,name=task")
+	for i := 0; i < 20; i++ {
+		got, err := swap.Apply([]byte(`{"task":"Read","name":"Gym"}`))
+		if err != nil || string(got) != `{"name":"Read","task":"Gym"}` {
+			t.Fatalf("swap: got %s (%v)", got, err)
+		}
+	}
+	shadow, _ := ParseInputAdapter("rename:task=name")
+	if got, _ := shadow.Apply([]byte(`{"task":"Read","name":"Gym"}`)); string(got) != `{"name":"Read"}` {
+		t.Fatalf("renamed key should replace the existing one, got %s", got)
+	}
+	adapter, _ := ParseInputAdapter("text:{{.missing}}")
+	if _, err := adapter.Apply(payload); err == nil {
+		t.Fatalf("expected missing key error")
+	}
+}
+
+func TestInputAdapterConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(EnvConfigPath, path)
+	text, _ := ParseInputAdapter("text:{{.task}}")
+	rename, _ := ParseInputAdapter("rename:task=name")
+	cfg := DefaultConfig()
+	cfg.Mappings["task-complete"] = ShortcutRef{Name: "Done", Input: &text, Fallbacks: []ShortcutRef{{Name: "Done v2", Input: &rename}}}
+	if _, err := Write(cfg); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	loaded, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ref := loaded.Mappings["task-complete"]
+	if ref.Input == nil || ref.Input.String() != "text:{{.task}}" {
+		t.Fatalf("unexpected input adapter: %+v", ref.Input)
+	}
+	if fb := ref.Fallbacks[0]; fb.Input == nil || fb.Input.Kind != AdapterRename || fb.Input.Rename["task"] != "name" {
+		t.Fatalf("unexpected fallback adapter: %+v", fb.Input)
+	}
+}
+
+func TestExtractors(t *testing.T) {
+	cases := []struct {
+		spec string
+		out  string
+		want string
+	}{
+		{"lines", "Read\n\n  Gym \n", `["Read","Gym"]`},
+		{"lines", `"Read\nGym"`, `["Read","Gym"]`},
+		{"split:,", "Read, Gym", `["Read","Gym"]`},
+		{"lines", `["Read","Gym"]`, `["Read","Gym"]`},
+		{"lines", `[{"title": "Read"}, 7]`, `["{\"title\":\"Read\"}","7"]`},
+		{"lines", "42", `["42"]`},
+		{"split:,", `{"a":1}`, `["{\"a\":1}"]`},
+		{"path:result.tasks.1.title", `{"result":{"tasks":[{"title":"Read"},{"title":"Gym"}]}}`, `"Gym"`},
+		{"template:{{.title}} ({{.streak}})", `{"title":"Read","streak":3}`, `"Read (3)"`},
+		{"template:[{{.output}}]", "done\n", `"[done]"`},
+	}
+	for _, tc := range cases {
+		extractor, err := ParseExtractor(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		got, err := extractor.Apply([]byte(tc.out))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if data, _ := json.Marshal(got); string(data) != tc.want {
+			t.Fatalf("%s: got %s, want %s", tc.spec, data, tc.want)
+		}
+	}
+	for _, spec := range []string{"xml", "path:", "lines:x"} {
+		if _, err := ParseExtractor(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+	extractor, _ := ParseExtractor("path:tasks")
+	if _, err := extractor.Apply([]byte("plain text")); err == nil {
+		t.Fatalf("path should need JSON output")
+	}
+	if _, err := extractor.Apply([]byte(`{"items":[]}`)); err == nil {
+		t.Fatalf("missing key should fail")
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Input adapter kinds.
+const (
+	AdapterJSON   = "json"
+	AdapterText   = "text"
+	AdapterRename = "rename"
+)
+
+// InputAdapter reshapes an action payload before it is handed to a shortcut.
+// In config it is written as "json", "text:<Go template>" or an object
+// renaming payload keys, e.g. {"task": "name"}.
+type InputAdapter struct {
+	Kind     string
+	Template string
+	Rename   map[string]string
+}
+
+// ParseInputAdapter parses the flag form: "json", "text:<template>" or
+// "rename:old=new,old2=new2".
+func ParseInputAdapter(value string) (InputAdapter, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "" || value == AdapterJSON:
+		return InputAdapter{Kind: AdapterJSON}, nil
+	case strings.HasPrefix(value, AdapterText+":"):
+		adapter := InputAdapter{Kind: AdapterText, Template: strings.TrimPrefix(value, AdapterText+":")}
+		if _, err := adapter.template(); err != nil {
+			return InputAdapter{}, err
+		}
+		return adapter, nil
+	case strings.HasPrefix(value, AdapterRename+":"):
+		rename := make(map[string]string)
+		for _, pair := range strings.Split(strings.TrimPrefix(value, AdapterRename+":"), ",") {
+			from, to, ok := strings.Cut(pair, "=")
+			from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+			if !ok || from == "" || to == "" {
+				return InputAdapter{}, fmt.Errorf("invalid rename %q (expected old=new)", pair)
+			}
+			if _, dup := rename[from]; dup {
+				return InputAdapter{}, fmt.Errorf("invalid rename: %q is renamed twice", from)
+			}
+			rename[from] = to
+		}
+		if err := validateRename(rename); err != nil {
+			return InputAdapter{}, err
+		}
+		return InputAdapter{Kind: AdapterRename, Rename: rename}, nil
+	}
+	return InputAdapter{}, fmt.Errorf("unknown input adapter %q (expected json, text:<template> or rename:old=new)", value)
+}
+
+func (a InputAdapter) String() string {
+	switch a.Kind {
+	case AdapterText:
+		return AdapterText + ":" + a.Template
+	case AdapterRename:
+		pairs := make([]string, 0, len(a.Rename))
+		for from, to := range a.Rename {
+			pairs = append(pairs, from+"="+to)
+		}
+		sort.Strings(pairs)
+		return AdapterRename + ":" + strings.Join(pairs, ",")
+	}
+	return AdapterJSON
+}
+
+func (a InputAdapter) MarshalJSON() ([]byte, error) {
+	if a.Kind == AdapterRename {
+		return json.Marshal(a.Rename)
+	}
+	return json.Marshal(a.String())
+}
+
+func (a *InputAdapter) UnmarshalJSON(data []byte) error {
+	var rename map[string]string
+	if err := json.Unmarshal(data, &rename); err == nil {
+		if err := validateRename(rename); err != nil {
+			return err
+		}
+		*a = InputAdapter{Kind: AdapterRename, Rename: rename}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("input adapter must be a string or an object of key renames")
+	}
+	parsed, err := ParseInputAdapter(value)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Apply reshapes payload. JSON objects are exposed to text templates by key;
+// any other payload is available as {{.input}}. Rename leaves non-object
+// payloads unchanged.
+func (a InputAdapter) Apply(payload []byte) ([]byte, error) {
+	switch a.Kind {
+	case AdapterText:
+		tmpl, err := a.template()
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, templateFields(payload, "input")); err != nil {
+			return nil, fmt.Errorf("input adapter: %w", err)
+		}
+		return out.Bytes(), nil
+	case AdapterRename:
+		var fields map[string]any
+		if len(bytes.TrimSpace(payload)) == 0 || json.Unmarshal(payload, &fields) != nil {
+			return payload, nil
+		}
+		// Renamed keys replace any key already using the new name.
+		renamed := make(map[string]any, len(fields))
+		for key, value := range fields {
+			if _, ok := a.Rename[key]; !ok {
+				renamed[key] = value
+			}
+		}
+		for from, to := range a.Rename {
+			if value, ok := fields[from]; ok {
+				renamed[to] = value
+			}
+		}
+		return json.Marshal(renamed)
+	}
+	return payload, nil
+}
+
+// validateRename rejects empty keys and two keys renamed to the same name,
+// which would leave the surviving value up to map order.
+func validateRename(rename map[string]string) error {
+	from := make([]string, 0, len(rename))
+	for key := range rename {
+		from = append(from, key)
+	}
+	sort.Strings(from)
+	seen := make(map[string]string, len(rename))
+	for _, key := range from {
+		to := rename[key]
+		if strings.TrimSpace(key) == "" || strings.TrimSpace(to) == "" {
+			return fmt.Errorf("invalid rename %q: %q (expected old=new)", key, to)
+		}
+		if other, dup := seen[to]; dup {
+			return fmt.Errorf("invalid rename: %q and %q are both renamed to %q", other, key, to)
+		}
+		seen[to] = key
+	}
+	return nil
+}
+
+func (a InputAdapter) template() (*template.Template, error) {
+	tmpl, err := template.New("input").Option("missingkey=error").Parse(a.Template)
+	if err != nil {
+		return nil, fmt.Errorf("input adapter template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateFields exposes a JSON object's keys to a template; any other data is
+// available under key, parsed when it is JSON.
+func templateFields(data []byte, key string) map[string]any {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return map[string]any{}
+	}
+	var payload any
+	if err := json.Unmarshal(trimmed, &payload); err != nil {
+		return map[string]any{key: string(trimmed)}
+	}
+	if fields, ok := payload.(map[string]any); ok {
+		return fields
+	}
+	return map[string]any{key: payload}
+}
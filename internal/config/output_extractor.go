@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Output extractor kinds.
+const (
+	ExtractLines    = "lines"
+	ExtractSplit    = "split"
+	ExtractPath     = "path"
+	ExtractTemplate = "template"
+)
+
+// Extractor turns raw shortcut output into the envelope result. It is written
+// as "lines", "split:<separator>", "path:<key.path>" or "template:<Go template>".
+type Extractor struct {
+	Kind string
+	Arg  string
+}
+
+// ParseExtractor parses an extractor spec.
+func ParseExtractor(value string) (Extractor, error) {
+	value = strings.TrimSpace(value)
+	kind, arg, _ := strings.Cut(value, ":")
+	extractor := Extractor{Kind: kind, Arg: arg}
+	switch kind {
+	case ExtractLines:
+		if arg != "" {
+			return Extractor{}, fmt.Errorf("extractor lines takes no argument")
+		}
+	case ExtractSplit, ExtractPath:
+		if arg == "" {
+			return Extractor{}, fmt.Errorf("extractor %s needs an argument (%s:<value>)", kind, kind)
+		}
+	case ExtractTemplate:
+		if _, err := extractor.template(); err != nil {
+			return Extractor{}, err
+		}
+	default:
+		return Extractor{}, fmt.Errorf("unknown extractor %q (expected lines, split:<sep>, path:<key.path> or template:<template>)", value)
+	}
+	return extractor, nil
+}
+
+func (e Extractor) String() string {
+	if e.Arg == "" {
+		return e.Kind
+	}
+	return e.Kind + ":" + e.Arg
+}
+
+func (e Extractor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+func (e *Extractor) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("extractor must be a string")
+	}
+	parsed, err := ParseExtractor(value)
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
+
+// Apply extracts a result from raw output. Lines and split always return a
+// list of strings: a JSON string is unquoted and split, a JSON array gives its
+// elements (non-strings as compact JSON), and anything else is split as
+// text. Path needs JSON output, and templates see JSON object keys or
+// {{.output}}.
+func (e Extractor) Apply(out []byte) (any, error) {
+	switch e.Kind {
+	case ExtractLines, ExtractSplit:
+		var items []json.RawMessage
+		if err := json.Unmarshal(bytes.TrimSpace(out), &items); err == nil {
+			return arrayStrings(items), nil
+		}
+		sep := e.Arg
+		if e.Kind == ExtractLines {
+			sep = "\n"
+		}
+		return splitOutput(outputText(out), sep), nil
+	case ExtractPath:
+		var payload any
+		if err := json.Unmarshal(bytes.TrimSpace(out), &payload); err != nil {
+			return nil, fmt.Errorf("output is not JSON")
+		}
+		return lookupPath(payload, e.Arg)
+	case ExtractTemplate:
+		tmpl, err := e.template()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, templateFields(out, "output")); err != nil {
+			return nil, fmt.Errorf("extractor: %w", err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+	return nil, fmt.Errorf("unknown extractor %q", e.Kind)
+}
+
+func (e Extractor) template() (*template.Template, error) {
+	tmpl, err := template.New("extract").Option("missingkey=error").Parse(e.Arg)
+	if err != nil {
+		return nil, fmt.Errorf("extractor template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func outputText(out []byte) string {
+	var text string
+	if err := json.Unmarshal(bytes.TrimSpace(out), &text); err == nil {
+		return text
+	}
+	return string(out)
+}
+
+func arrayStrings(items []json.RawMessage) []string {
+	parts := []string{}
+	for _, item := range items {
+		part := outputText(item)
+		var compact bytes.Buffer
+		if json.Compact(&compact, item) == nil && !strings.HasPrefix(compact.String(), `"`) {
+			part = compact.String()
+		}
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func splitOutput(text, sep string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(text, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// lookupPath walks dot-separated keys; numeric segments index arrays.
+func lookupPath(payload any, path string) (any, error) {
+	current := payload
+	for _, key := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found in output", key)
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("index %q out of range in output", key)
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("key %q not found in output", key)
+		}
+	}
+	return current, nil
+}
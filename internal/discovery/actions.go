@@ -9,6 +9,10 @@ type ActionDef struct {
 	RequiresTask bool
 	Keys         []string
 	ParamOptions map[string][]string
+	// OutputType is the default output UTI and Extract the default output
+	// extractor spec; mappings can override both.
+	OutputType string
+	Extract    string
 }
 
 const (
@@ -16,6 +20,13 @@ const (
 	TransportURLScheme = "url-scheme"
 )
 
+// Output UTIs used by the action defaults.
+const (
+	OutputJSON = "public.json"
+	OutputCSV  = "public.comma-separated-values-text"
+	OutputZip  = "public.zip-archive"
+)
+
 func DefaultActionDefinitions() []ActionDef {
 	return []ActionDef{
 		{
@@ -52,6 +63,8 @@ func DefaultActionDefinitions() []ActionDef {
 			Keys: []string{
 				"AppIntent.TaskList.AllTasks",
 			},
+			OutputType: OutputJSON,
+			Extract:    "lines",
 		},
 		{
 			ID:           "task-status",
@@ -61,6 +74,7 @@ func DefaultActionDefinitions() []ActionDef {
 			Keys: []string{
 				"AppIntent.Status.StatusOf${task}",
 			},
+			OutputType: OutputJSON,
 		},
 		{
 			ID:           "task-reminder",
@@ -110,6 +124,7 @@ func DefaultActionDefinitions() []ActionDef {
 			Keys: []string{
 				"AppIntent.DataExport.ExportAllData",
 			},
+			OutputType: OutputZip,
 		},
 		{
 			ID:           "export-task",
@@ -119,6 +134,7 @@ func DefaultActionDefinitions() []ActionDef {
 			Keys: []string{
 				"AppIntent.DataExport.Export${task}Data",
 			},
+			OutputType: OutputCSV,
 		},
 	}
 }
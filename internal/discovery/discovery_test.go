@@ -158,3 +158,31 @@ func TestActionShortcutCandidates(t *testing.T) {
 		t.Fatalf("unexpected candidates: %v", candidates)
 	}
 }
+
+func TestActionShortcutCandidateDetails(t *testing.T) {
+	def := ActionDef{
+		ID:           "task-complete",
+		Title:        "Complete ${task}",
+		Transport:    TransportShortcuts,
+		RequiresTask: true,
+		Keys:         []string{"AppIntent.CompleteTask.Title"},
+	}
+	t.Setenv("LANG", "en_US.UTF-8")
+	intentKeys := []AppIntentKey{{Key: "AppIntent.CompleteTask.Title", Value: "Complete Task", Locale: "en"}}
+	phrases := []AppIntentKey{{Key: "AppIntent.CompleteTask.Mark[0]", Value: "Mark ${task} done in ${applicationName}", Locale: "en"}}
+
+	got := ActionShortcutCandidateDetails(def, AppInfo{Name: "Streaks"}, intentKeys, phrases, "Read")
+	want := []ShortcutCandidate{
+		{Name: "Complete Read", Origin: OriginTitle, Template: "Complete ${task}"},
+		{Name: "Complete Task", Origin: OriginIntentKey, Key: "AppIntent.CompleteTask.Title", Locale: "en"},
+		{Name: "Mark Read done in Streaks", Origin: OriginPhrase, Template: "Mark ${task} done in ${applicationName}", Key: "AppIntent.CompleteTask.Mark[0]", Locale: "en"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidate %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
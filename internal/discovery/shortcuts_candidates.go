@@ -5,7 +5,35 @@ import (
 	"strings"
 )
 
+// Candidate origins reported by ActionShortcutCandidateDetails.
+const (
+	OriginTitle     = "title"
+	OriginIntentKey = "intent_key"
+	OriginPhrase    = "phrase"
+)
+
+// ShortcutCandidate is a shortcut name derived for an action together with
+// where it came from.
+type ShortcutCandidate struct {
+	Name     string `json:"name"`
+	Origin   string `json:"origin"`
+	Template string `json:"template,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+}
+
 func ActionShortcutCandidates(def ActionDef, app AppInfo, intentKeys []AppIntentKey, shortcutPhrases []AppIntentKey, task string) []string {
+	details := ActionShortcutCandidateDetails(def, app, intentKeys, shortcutPhrases, task)
+	candidates := make([]string, 0, len(details))
+	for _, cand := range details {
+		candidates = append(candidates, cand.Name)
+	}
+	return candidates
+}
+
+// ActionShortcutCandidateDetails is ActionShortcutCandidates with the origin
+// of every candidate.
+func ActionShortcutCandidateDetails(def ActionDef, app AppInfo, intentKeys []AppIntentKey, shortcutPhrases []AppIntentKey, task string) []ShortcutCandidate {
 	if def.Transport != TransportShortcuts {
 		return nil
 	}
@@ -13,16 +41,87 @@ func ActionShortcutCandidates(def ActionDef, app AppInfo, intentKeys []AppIntent
 	if appName == "" {
 		appName = "Streaks"
 	}
-	intentValues := orderedValues(intentKeys, PreferredLocales())
-	intentNames := actionIntentNames(def)
+	templates := make([]originTemplate, 0, len(def.Keys)+len(shortcutPhrases)+1)
+	if def.Title != "" && shouldUseTitleTemplate(def) {
+		templates = append(templates, originTemplate{origin: OriginTitle, template: def.Title})
+	}
+	templates = append(templates, localizedOriginTemplates(def, intentKeys, shortcutPhrases)...)
 
+	seen := make(map[string]struct{}, len(templates))
+	candidates := make([]ShortcutCandidate, 0, len(templates))
+	for _, tmpl := range templates {
+		if tmpl.template == "" {
+			continue
+		}
+		out, ok := expandShortcutTemplate(tmpl.template, task, appName)
+		if !ok {
+			continue
+		}
+		if _, exists := seen[out]; exists {
+			continue
+		}
+		seen[out] = struct{}{}
+		cand := ShortcutCandidate{Name: out, Origin: tmpl.origin, Key: tmpl.key, Locale: tmpl.locale}
+		if tmpl.template != out {
+			cand.Template = tmpl.template
+		}
+		candidates = append(candidates, cand)
+	}
+	return candidates
+}
+
+// ActionPhraseTemplates returns the phrases a user might say for an action, in
+// preferred-locale order. Titles of task actions get a ${task} slot in place of
+// the word "task" so they can be matched like the app's own phrases.
+func ActionPhraseTemplates(def ActionDef, intentKeys []AppIntentKey, shortcutPhrases []AppIntentKey) []string {
 	templates := make([]string, 0, len(def.Keys)+len(shortcutPhrases)+1)
-	if def.Title != "" && shouldUseTitleTemplate(def) {
-		templates = append(templates, def.Title)
+	templates = append(templates, localizedTemplates(def, intentKeys, shortcutPhrases)...)
+	if def.Title != "" {
+		title := def.Title
+		if def.RequiresTask && !strings.Contains(title, "${task}") && !strings.Contains(title, "%@") {
+			title = replaceWord(title, "task", "${task}")
+		}
+		templates = append(templates, title)
 	}
+	seen := make(map[string]struct{}, len(templates))
+	out := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		if tmpl == "" {
+			continue
+		}
+		if _, ok := seen[tmpl]; ok {
+			continue
+		}
+		seen[tmpl] = struct{}{}
+		out = append(out, tmpl)
+	}
+	return out
+}
+
+type originTemplate struct {
+	origin   string
+	template string
+	key      string
+	locale   string
+}
+
+func localizedTemplates(def ActionDef, intentKeys []AppIntentKey, shortcutPhrases []AppIntentKey) []string {
+	origins := localizedOriginTemplates(def, intentKeys, shortcutPhrases)
+	templates := make([]string, 0, len(origins))
+	for _, tmpl := range origins {
+		templates = append(templates, tmpl.template)
+	}
+	return templates
+}
+
+func localizedOriginTemplates(def ActionDef, intentKeys []AppIntentKey, shortcutPhrases []AppIntentKey) []originTemplate {
+	intentValues := orderedValues(intentKeys, PreferredLocales())
+	intentNames := actionIntentNames(def)
+
+	templates := make([]originTemplate, 0, len(def.Keys)+len(shortcutPhrases))
 	for _, key := range def.Keys {
-		if values, ok := intentValues[key]; ok {
-			templates = append(templates, values...)
+		for _, value := range intentValues[key] {
+			templates = append(templates, originTemplate{origin: OriginIntentKey, template: value.value, key: key, locale: value.locale})
 		}
 	}
 	phraseValues := orderedValues(shortcutPhrases, PreferredLocales())
@@ -34,29 +133,25 @@ func ActionShortcutCandidates(def ActionDef, app AppInfo, intentKeys []AppIntent
 	for _, key := range phraseKeys {
 		for _, intent := range intentNames {
 			if strings.Contains(key, "AppIntent."+intent+".") {
-				templates = append(templates, phraseValues[key]...)
+				for _, value := range phraseValues[key] {
+					templates = append(templates, originTemplate{origin: OriginPhrase, template: value.value, key: key, locale: value.locale})
+				}
 				break
 			}
 		}
 	}
+	return templates
+}
 
-	seen := make(map[string]struct{}, len(templates))
-	candidates := make([]string, 0, len(templates))
-	for _, tmpl := range templates {
-		if tmpl == "" {
-			continue
-		}
-		out, ok := expandShortcutTemplate(tmpl, task, appName)
-		if !ok {
-			continue
-		}
-		if _, exists := seen[out]; exists {
-			continue
+func replaceWord(s, word, replacement string) string {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		if strings.EqualFold(field, word) {
+			fields[i] = replacement
+			return strings.Join(fields, " ")
 		}
-		seen[out] = struct{}{}
-		candidates = append(candidates, out)
 	}
-	return candidates
+	return s
 }
 
 func shouldUseTitleTemplate(def ActionDef) bool {
@@ -104,7 +199,14 @@ func expandShortcutTemplate(tmpl, task, appName string) (string, bool) {
 	return out, true
 }
 
-func orderedValues(keys []AppIntentKey, preferred []string) map[string][]string {
+type localizedValue struct {
+	locale string
+	value  string
+}
+
+// orderedValues groups values by key in locale priority order, dropping
+// duplicate values.
+func orderedValues(keys []AppIntentKey, preferred []string) map[string][]localizedValue {
 	byKey := make(map[string]map[string]string)
 	for _, entry := range keys {
 		if entry.Value == "" {
@@ -119,7 +221,7 @@ func orderedValues(keys []AppIntentKey, preferred []string) map[string][]string
 		}
 	}
 
-	out := make(map[string][]string, len(byKey))
+	out := make(map[string][]localizedValue, len(byKey))
 	for key, valuesByLocale := range byKey {
 		available := make(map[string]struct{}, len(valuesByLocale))
 		for locale := range valuesByLocale {
@@ -127,14 +229,14 @@ func orderedValues(keys []AppIntentKey, preferred []string) map[string][]string
 		}
 		order := localePriority(preferred, available)
 		seen := make(map[string]struct{})
-		ordered := make([]string, 0, len(valuesByLocale))
+		ordered := make([]localizedValue, 0, len(valuesByLocale))
 		for _, locale := range order {
 			if value, ok := valuesByLocale[locale]; ok {
 				if _, dup := seen[value]; dup {
 					continue
 				}
 				seen[value] = struct{}{}
-				ordered = append(ordered, value)
+				ordered = append(ordered, localizedValue{locale: locale, value: value})
 			}
 		}
 		out[key] = ordered
@@ -0,0 +1,143 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"streaks-cli/internal/config"
+)
+
+const (
+	DefaultFileName = "journal.jsonl"
+	EnvJournalPath  = "STREAKS_CLI_JOURNAL"
+)
+
+type Entry struct {
+	Timestamp  string `json:"timestamp"`
+	Action     string `json:"action"`
+	Task       string `json:"task,omitempty"`
+	Note       string `json:"note"`
+	Shortcut   string `json:"shortcut"`
+	Count      int    `json:"count,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Input      any    `json:"input,omitempty"`
+	Result     any    `json:"result,omitempty"`
+}
+
+type Filter struct {
+	Task  string
+	Text  string
+	Since time.Time
+	Until time.Time
+}
+
+func Path() (string, error) {
+	if override := os.Getenv(EnvJournalPath); override != "" {
+		return override, nil
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultFileName), nil
+}
+
+func Append(entry Entry) (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	if entry.Timestamp == "" {
+		entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func Select(entries []Entry, filter Filter) []Entry {
+	out := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if filter.Match(entry) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func (f Filter) Match(entry Entry) bool {
+	if task := strings.TrimSpace(f.Task); task != "" && !strings.EqualFold(strings.TrimSpace(entry.Task), task) {
+		return false
+	}
+	if text := strings.ToLower(strings.TrimSpace(f.Text)); text != "" {
+		haystack := strings.ToLower(entry.Note + "\n" + entry.Task)
+		if !strings.Contains(haystack, text) {
+			return false
+		}
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && ts.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && !ts.Before(f.Until) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package journal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"streaks-cli/internal/config"
+)
+
+func TestPathFollowsConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.EnvConfigPath, filepath.Join(dir, "config.json"))
+	t.Setenv(EnvJournalPath, "")
+	got, err := Path()
+	if err != nil {
+		t.Fatalf("Path: %v", err)
+	}
+	if want := filepath.Join(dir, DefaultFileName); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv(EnvJournalPath, filepath.Join(t.TempDir(), "journal.jsonl"))
+	for _, entry := range []Entry{
+		{Action: "task-complete", Task: "Read", Note: "read ch. 4", Shortcut: "Complete Task"},
+		{Action: "task-miss", Task: "Run", Note: "rest day", Shortcut: "Mark Task Missed"},
+	} {
+		if _, err := Append(entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	entries, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Timestamp == "" || entries[0].Note != "read ch. 4" {
+		t.Fatalf("unexpected entry: %+v", entries[0])
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	base := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Timestamp: base.Format(time.RFC3339Nano), Task: "Read", Note: "read ch. 4"},
+		{Timestamp: base.Add(24 * time.Hour).Format(time.RFC3339Nano), Task: "Run", Note: "5km run"},
+		{Timestamp: base.Add(48 * time.Hour).Format(time.RFC3339Nano), Task: "read", Note: "ch. 5"},
+	}
+	if got := Select(entries, Filter{Task: "READ"}); len(got) != 2 {
+		t.Fatalf("task filter: expected 2, got %d", len(got))
+	}
+	if got := Select(entries, Filter{Text: "5KM"}); len(got) != 1 || got[0].Task != "Run" {
+		t.Fatalf("text filter: unexpected %+v", got)
+	}
+	got := Select(entries, Filter{Since: base.Add(time.Hour), Until: base.Add(48 * time.Hour)})
+	if len(got) != 1 || got[0].Task != "Run" {
+		t.Fatalf("range filter: unexpected %+v", got)
+	}
+}
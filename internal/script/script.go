@@ -0,0 +1,204 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+const DefaultMaxSteps = 10_000_000
+
+// Host executes the side effects a script asks for. Scripts have no other way
+// to reach the filesystem, network or processes.
+type Host interface {
+	Tasks(ctx context.Context) ([]string, error)
+	Status(ctx context.Context, task string) (map[string]any, error)
+	Run(ctx context.Context, call Call) (map[string]any, error)
+	Now() time.Time
+}
+
+type Call struct {
+	Action string
+	Task   string
+	Status string
+	Note   string
+}
+
+type Options struct {
+	MaxSteps uint64
+	Print    func(msg string)
+}
+
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+}
+
+func Exec(ctx context.Context, filename string, src []byte, host Host, opts Options) error {
+	thread := &starlark.Thread{
+		Name: filename,
+		Print: func(_ *starlark.Thread, msg string) {
+			if opts.Print != nil {
+				opts.Print(msg)
+			}
+		},
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load(%q): loading modules is disabled", module)
+		},
+	}
+	maxSteps := opts.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	starlarktime.SetNow(thread, func() (time.Time, error) { return host.Now(), nil })
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	_, err := starlark.ExecFileOptions(fileOptions, thread, filename, src, builtins(ctx, host))
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return &RuntimeError{Message: evalErr.Backtrace()}
+		}
+		return err
+	}
+	return nil
+}
+
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+func builtins(ctx context.Context, host Host) starlark.StringDict {
+	action := func(name, actionID string) *starlark.Builtin {
+		return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var task, note string
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "task", &task, "note?", &note); err != nil {
+				return nil, err
+			}
+			result, err := host.Run(ctx, Call{Action: actionID, Task: task, Note: note})
+			if err != nil {
+				return nil, err
+			}
+			return toValue(result), nil
+		})
+	}
+	return starlark.StringDict{
+		"tasks": starlark.NewBuiltin("tasks", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+				return nil, err
+			}
+			tasks, err := host.Tasks(ctx)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]starlark.Value, 0, len(tasks))
+			for _, task := range tasks {
+				values = append(values, starlark.String(task))
+			}
+			return starlark.NewList(values), nil
+		}),
+		"status": starlark.NewBuiltin("status", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var task string
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "task", &task); err != nil {
+				return nil, err
+			}
+			status, err := host.Status(ctx, task)
+			if err != nil {
+				return nil, err
+			}
+			return toValue(status), nil
+		}),
+		"complete":    action("complete", "task-complete"),
+		"miss":        action("miss", "task-miss"),
+		"remind":      action("remind", "task-reminder"),
+		"start_timer": action("start_timer", "timer-start"),
+		"stop_timer":  action("stop_timer", "timer-stop"),
+		"run": starlark.NewBuiltin("run", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var call Call
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "action", &call.Action, "task?", &call.Task, "status?", &call.Status, "note?", &call.Note); err != nil {
+				return nil, err
+			}
+			result, err := host.Run(ctx, call)
+			if err != nil {
+				return nil, err
+			}
+			return toValue(result), nil
+		}),
+		"now": starlark.NewBuiltin("now", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+				return nil, err
+			}
+			return starlarktime.Time(host.Now()), nil
+		}),
+		"time": starlarktime.Module,
+	}
+}
+
+func toValue(v any) starlark.Value {
+	switch x := v.(type) {
+	case nil:
+		return starlark.None
+	case starlark.Value:
+		return x
+	case bool:
+		return starlark.Bool(x)
+	case int:
+		return starlark.MakeInt(x)
+	case int64:
+		return starlark.MakeInt64(x)
+	case float64:
+		if x == float64(int64(x)) {
+			return starlark.MakeInt64(int64(x))
+		}
+		return starlark.Float(x)
+	case string:
+		return starlark.String(x)
+	case []string:
+		values := make([]starlark.Value, 0, len(x))
+		for _, item := range x {
+			values = append(values, starlark.String(item))
+		}
+		return starlark.NewList(values)
+	case []any:
+		values := make([]starlark.Value, 0, len(x))
+		for _, item := range x {
+			values = append(values, toValue(item))
+		}
+		return starlark.NewList(values)
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dict := starlark.NewDict(len(x))
+		for _, key := range keys {
+			_ = dict.SetKey(starlark.String(key), toValue(x[key]))
+		}
+		return dict
+	default:
+		return starlark.String(fmt.Sprint(x))
+	}
+}
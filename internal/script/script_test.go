@@ -0,0 +1,84 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeHost struct {
+	calls  []Call
+	status map[string]string
+}
+
+func (h *fakeHost) Tasks(context.Context) ([]string, error) {
+	return []string{"Meditate", "Read"}, nil
+}
+
+func (h *fakeHost) Status(_ context.Context, task string) (map[string]any, error) {
+	return map[string]any{"task": task, "status": h.status[task]}, nil
+}
+
+func (h *fakeHost) Run(_ context.Context, call Call) (map[string]any, error) {
+	h.calls = append(h.calls, call)
+	return map[string]any{"ok": true, "attempts": float64(1)}, nil
+}
+
+func (h *fakeHost) Now() time.Time {
+	return time.Date(2026, 3, 1, 20, 30, 0, 0, time.UTC)
+}
+
+func TestExecBuiltins(t *testing.T) {
+	host := &fakeHost{status: map[string]string{"Meditate": "done", "Read": "pending"}}
+	var printed []string
+	src := `
+for task in tasks():
+    if status(task)["status"] == "pending":
+        result = complete(task, note="auto")
+        print(task, result["ok"], result["attempts"])
+if now().hour >= 20:
+    remind("Meditate")
+run("timer-start", task="Read")
+`
+	err := Exec(context.Background(), "test.star", []byte(src), host, Options{Print: func(msg string) { printed = append(printed, msg) }})
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	want := []Call{
+		{Action: "task-complete", Task: "Read", Note: "auto"},
+		{Action: "task-reminder", Task: "Meditate"},
+		{Action: "timer-start", Task: "Read"},
+	}
+	if len(host.calls) != len(want) {
+		t.Fatalf("unexpected calls: %+v", host.calls)
+	}
+	for i := range want {
+		if host.calls[i] != want[i] {
+			t.Fatalf("call %d = %+v, want %+v", i, host.calls[i], want[i])
+		}
+	}
+	if len(printed) != 1 || printed[0] != "Read True 1" {
+		t.Fatalf("unexpected print output: %q", printed)
+	}
+}
+
+func TestExecSandbox(t *testing.T) {
+	host := &fakeHost{}
+	err := Exec(context.Background(), "load.star", []byte(`load("os.star", "system")`), host, Options{})
+	if err == nil || !strings.Contains(err.Error(), "loading modules is disabled") {
+		t.Fatalf("expected load to be disabled, got %v", err)
+	}
+
+	err = Exec(context.Background(), "open.star", []byte(`open("/etc/passwd")`), host, Options{})
+	if err == nil || !strings.Contains(err.Error(), "undefined: open") {
+		t.Fatalf("expected open to be undefined, got %v", err)
+	}
+
+	err = Exec(context.Background(), "loop.star", []byte("while True:\n    pass\n"), host, Options{MaxSteps: 1000})
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || !strings.Contains(err.Error(), "too many steps") {
+		t.Fatalf("expected step limit error, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package shortcuts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInlineLimit is the largest text output returned inline.
+const DefaultInlineLimit = 1 << 20
+
+// CacheRetention is how long run directories under the output cache are kept
+// when neither OutputDir nor SavePath is set.
+const CacheRetention = 7 * 24 * time.Hour
+
+// OutputFile references a shortcut output file saved to disk.
+type OutputFile struct {
+	Path   string `json:"path"`
+	MIME   string `json:"mime"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// readOutputDir returns the files a shortcut wrote to dir: a single inline
+// file as is, several as a JSON array. Binary files, text over the inline
+// limit and, with SavePath, every file are moved out of dir and returned as
+// OutputFile references.
+func readOutputDir(dir string, opts RunOptions) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		files = append(files, name)
+	}
+	if len(files) == 0 {
+		return []byte{}, nil
+	}
+	sort.Strings(files)
+
+	saver := &outputSaver{opts: opts, single: len(files) == 1}
+	items := make([]any, 0, len(files))
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		data, inline, err := saver.readInline(path)
+		if err != nil {
+			return nil, err
+		}
+		if !inline {
+			ref, err := saver.save(path)
+			if err != nil {
+				return nil, err
+			}
+			if saver.single {
+				return json.Marshal(ref)
+			}
+			items = append(items, ref)
+			continue
+		}
+		if saver.single {
+			return data, nil
+		}
+		var payload any
+		if err := json.Unmarshal(data, &payload); err == nil {
+			items = append(items, payload)
+			continue
+		}
+		items = append(items, strings.TrimSpace(string(data)))
+	}
+	return json.Marshal(items)
+}
+
+type outputSaver struct {
+	opts   RunOptions
+	single bool
+	// cacheDir is the per-run directory under the user cache dir.
+	cacheDir string
+}
+
+// readInline returns the contents of path when it is text within the inline
+// limit and SavePath is not set.
+func (s *outputSaver) readInline(path string) ([]byte, bool, error) {
+	if s.opts.SavePath != "" {
+		return nil, false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, err
+	}
+	limit := s.opts.InlineLimit
+	if limit <= 0 {
+		limit = DefaultInlineLimit
+	}
+	if info.Size() > limit {
+		return nil, false, nil
+	}
+	ctype, err := sniffFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !isTextType(ctype) {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(path)
+	return data, true, err
+}
+
+// save moves path to its destination and describes the saved file.
+func (s *outputSaver) save(path string) (OutputFile, error) {
+	dest, err := s.destination(filepath.Base(path))
+	if err != nil {
+		return OutputFile{}, err
+	}
+	if err := moveFile(path, dest); err != nil {
+		return OutputFile{}, err
+	}
+	return describeFile(dest)
+}
+
+// destination never replaces an existing file unless Overwrite is set; the
+// name gets a numeric suffix instead, for SavePath and OutputDir alike.
+func (s *outputSaver) destination(name string) (string, error) {
+	dest, err := s.target(name)
+	if err != nil || s.opts.Overwrite {
+		return dest, err
+	}
+	return uniquePath(dest), nil
+}
+
+func (s *outputSaver) target(name string) (string, error) {
+	switch {
+	case s.opts.SavePath != "":
+		if info, err := os.Stat(s.opts.SavePath); s.single && (err != nil || !info.IsDir()) {
+			if err := os.MkdirAll(filepath.Dir(s.opts.SavePath), 0o755); err != nil {
+				return "", err
+			}
+			return s.opts.SavePath, nil
+		}
+		if err := os.MkdirAll(s.opts.SavePath, 0o755); err != nil {
+			return "", err
+		}
+		return filepath.Join(s.opts.SavePath, name), nil
+	case s.opts.OutputDir != "":
+		if err := os.MkdirAll(s.opts.OutputDir, 0o755); err != nil {
+			return "", err
+		}
+		return filepath.Join(s.opts.OutputDir, name), nil
+	}
+	if s.cacheDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(base, "streaks-cli", "outputs")
+		if err := os.MkdirAll(base, 0o755); err != nil {
+			return "", err
+		}
+		pruneOutputCache(base, time.Now().Add(-CacheRetention))
+		if s.cacheDir, err = os.MkdirTemp(base, "run-*"); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(s.cacheDir, name), nil
+}
+
+// pruneOutputCache removes run directories last modified before cutoff.
+// Failures are ignored; a leftover directory only costs disk space.
+func pruneOutputCache(base string, cutoff time.Time) {
+	runs, err := filepath.Glob(filepath.Join(base, "run-*"))
+	if err != nil {
+		return
+	}
+	for _, run := range runs {
+		if info, err := os.Stat(run); err == nil && info.IsDir() && info.ModTime().Before(cutoff) {
+			_ = os.RemoveAll(run)
+		}
+	}
+}
+
+// uniquePath adds -1, -2, ... before the extension until path is unused.
+func uniquePath(path string) string {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	candidate := path
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+}
+
+func moveFile(src, dest string) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// describeFile hashes path and names its MIME type from the extension, else
+// its content.
+func describeFile(path string) (OutputFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return OutputFile{}, err
+	}
+	ctype := mime.TypeByExtension(filepath.Ext(abs))
+	if ctype == "" {
+		if ctype, err = sniffFile(abs); err != nil {
+			return OutputFile{}, err
+		}
+	}
+	if media, _, err := mime.ParseMediaType(ctype); err == nil {
+		ctype = media
+	}
+	return OutputFile{Path: abs, MIME: ctype, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// sniffFile detects the content type from the first 512 bytes of path.
+func sniffFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+func isTextType(ctype string) bool {
+	return strings.HasPrefix(ctype, "text/") || strings.HasPrefix(ctype, "application/json")
+}
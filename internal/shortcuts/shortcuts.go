@@ -4,13 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 )
 
@@ -19,21 +16,65 @@ type Shortcut struct {
 	ID   string `json:"id"`
 }
 
+// Input types for RunOptions.InputType.
+const (
+	InputJSON = "json"
+	InputText = "text"
+)
+
 type RunOptions struct {
 	OutputType string
+	// InputType is how the input bytes are handed over: InputJSON (the
+	// default) writes a .json file, InputText a .txt file.
+	InputType string
+	// InputFiles are passed to the shortcut as is, after the input bytes.
+	InputFiles []string
+	// OutputDir receives binary output and text output over InlineLimit;
+	// empty means a directory under the user cache dir.
+	OutputDir string
+	// InlineLimit is the largest text output kept inline (DefaultInlineLimit
+	// when zero).
+	InlineLimit int64
+	// SavePath saves every output file instead of inlining it: a single file
+	// to SavePath itself, several into SavePath as a directory.
+	SavePath string
+	// Overwrite lets saved files replace existing ones; otherwise a numeric
+	// suffix is added to the name.
+	Overwrite bool
 }
 
+// Binary is the macOS Shortcuts command line tool.
+const Binary = "/usr/bin/shortcuts"
+
 var listLine = regexp.MustCompile(`^(.*) \(([0-9A-Fa-f-]+)\)$`)
 
 func List(ctx context.Context) ([]Shortcut, error) {
-	cmd := exec.CommandContext(ctx, "/usr/bin/shortcuts", "list", "--show-identifiers")
+	return ListFolder(ctx, "")
+}
+
+// ListFolder lists the shortcuts in one Shortcuts folder, or the whole
+// library when folder is empty.
+func ListFolder(ctx context.Context, folder string) ([]Shortcut, error) {
+	cmd := exec.CommandContext(ctx, Binary, ListArgs(folder)...)
 	out, err := cmd.Output()
 	if err != nil {
+		if folder != "" {
+			return nil, fmt.Errorf("shortcuts list --folder-name %q failed: %w", folder, err)
+		}
 		return nil, fmt.Errorf("shortcuts list failed: %w", err)
 	}
 	return parseList(out), nil
 }
 
+// ListArgs returns the arguments ListFolder passes to the shortcuts binary.
+func ListArgs(folder string) []string {
+	args := []string{"list", "--show-identifiers"}
+	if folder = strings.TrimSpace(folder); folder != "" {
+		args = append(args, "--folder-name", folder)
+	}
+	return args
+}
+
 func parseList(output []byte) []Shortcut {
 	var shortcuts []Shortcut
 	scanner := bufio.NewScanner(bytes.NewReader(output))
@@ -69,34 +110,57 @@ func Run(ctx context.Context, name string, input []byte) ([]byte, error) {
 	return RunWithOptions(ctx, name, input, RunOptions{OutputType: "public.json"})
 }
 
+// RunWithOptions runs a shortcut with input written to a temporary file
+// (an empty JSON object when there is neither input nor input files).
 func RunWithOptions(ctx context.Context, name string, input []byte, opts RunOptions) ([]byte, error) {
-	inputPath := ""
-	outputDir := ""
-	var err error
-	if input == nil {
+	var inputPaths []string
+	if input == nil && len(opts.InputFiles) == 0 {
 		input = []byte("{}")
 	}
-	if inputPath, err = writeTempFile("streaks-cli-input-*.json", input); err != nil {
-		return nil, err
+	if input != nil {
+		inputPath, err := writeTempFile("streaks-cli-input-*"+InputExt(opts.InputType), input)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(inputPath)
+		inputPaths = append(inputPaths, inputPath)
 	}
-	defer os.Remove(inputPath)
+	inputPaths = append(inputPaths, opts.InputFiles...)
 
-	if outputDir, err = os.MkdirTemp("", "streaks-cli-output-*"); err != nil {
+	outputDir, err := os.MkdirTemp("", "streaks-cli-output-*")
+	if err != nil {
 		return nil, err
 	}
 	defer os.RemoveAll(outputDir)
 
-	args := []string{"run", name, "--input-path", inputPath, "--output-path", outputDir}
-	if strings.TrimSpace(opts.OutputType) != "" {
-		args = append(args, "--output-type", opts.OutputType)
-	}
-	cmd := exec.CommandContext(ctx, "/usr/bin/shortcuts", args...)
+	cmd := exec.CommandContext(ctx, Binary, RunArgs(name, inputPaths, outputDir, opts)...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("shortcuts run failed: %w: %s", err, strings.TrimSpace(stderr.String()))
 	}
-	return readOutputDir(outputDir)
+	return readOutputDir(outputDir, opts)
+}
+
+// RunArgs returns the arguments RunWithOptions passes to the shortcuts binary.
+func RunArgs(name string, inputPaths []string, outputDir string, opts RunOptions) []string {
+	args := []string{"run", name}
+	for _, path := range inputPaths {
+		args = append(args, "--input-path", path)
+	}
+	args = append(args, "--output-path", outputDir)
+	if strings.TrimSpace(opts.OutputType) != "" {
+		args = append(args, "--output-type", opts.OutputType)
+	}
+	return args
+}
+
+// InputExt is the temporary input file extension for an input type.
+func InputExt(inputType string) string {
+	if inputType == InputText {
+		return ".txt"
+	}
+	return ".json"
 }
 
 func writeTempFile(pattern string, data []byte) (string, error) {
@@ -110,43 +174,3 @@ func writeTempFile(pattern string, data []byte) (string, error) {
 	}
 	return f.Name(), nil
 }
-
-func readOutputDir(dir string) ([]byte, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
-	}
-	files := make([]string, 0, len(entries))
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-		files = append(files, name)
-	}
-	if len(files) == 0 {
-		return []byte{}, nil
-	}
-	sort.Strings(files)
-	if len(files) == 1 {
-		return os.ReadFile(filepath.Join(dir, files[0]))
-	}
-
-	items := make([]any, 0, len(files))
-	for _, name := range files {
-		data, err := os.ReadFile(filepath.Join(dir, name))
-		if err != nil {
-			return nil, err
-		}
-		var payload any
-		if err := json.Unmarshal(data, &payload); err == nil {
-			items = append(items, payload)
-			continue
-		}
-		items = append(items, strings.TrimSpace(string(data)))
-	}
-	return json.Marshal(items)
-}
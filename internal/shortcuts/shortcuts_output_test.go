@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReadOutputDirEmpty(t *testing.T) {
 	dir := t.TempDir()
-	got, err := readOutputDir(dir)
+	got, err := readOutputDir(dir, RunOptions{})
 	if err != nil {
 		t.Fatalf("readOutputDir returned error: %v", err)
 	}
@@ -24,7 +26,7 @@ func TestReadOutputDirSingleFile(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, "Dictionary.json"), payload, 0600); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
-	got, err := readOutputDir(dir)
+	got, err := readOutputDir(dir, RunOptions{})
 	if err != nil {
 		t.Fatalf("readOutputDir returned error: %v", err)
 	}
@@ -44,7 +46,7 @@ func TestReadOutputDirMultipleFiles(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, "c.json"), []byte(`{"b":2}`), 0600); err != nil {
 		t.Fatalf("write c.json: %v", err)
 	}
-	got, err := readOutputDir(dir)
+	got, err := readOutputDir(dir, RunOptions{})
 	if err != nil {
 		t.Fatalf("readOutputDir returned error: %v", err)
 	}
@@ -76,3 +78,137 @@ func TestReadOutputDirMultipleFiles(t *testing.T) {
 		t.Fatalf("unexpected third item: %#v", items[2])
 	}
 }
+
+func TestReadOutputDirSavesFiles(t *testing.T) {
+	write := func(dir, name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	dir, saved := t.TempDir(), t.TempDir()
+	write(dir, "a.json", []byte(`{"ok":true}`))
+	write(dir, "b.png", png)
+	write(dir, "c.txt", []byte(strings.Repeat("x", 64)))
+	out, err := readOutputDir(dir, RunOptions{OutputDir: saved, InlineLimit: 32})
+	if err != nil {
+		t.Fatalf("readOutputDir: %v", err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out, &items); err != nil || len(items) != 3 || string(items[0]) != `{"ok":true}` {
+		t.Fatalf("unexpected items: %s (%v)", out, err)
+	}
+	var image, large OutputFile
+	_ = json.Unmarshal(items[1], &image)
+	_ = json.Unmarshal(items[2], &large)
+	if image.Path != filepath.Join(saved, "b.png") || image.MIME != "image/png" || image.Size != int64(len(png)) || len(image.SHA256) != 64 {
+		t.Fatalf("unexpected image ref: %+v", image)
+	}
+	if large.Path != filepath.Join(saved, "c.txt") || large.Size != 64 {
+		t.Fatalf("large text should be saved, got %+v", large)
+	}
+
+	dir = t.TempDir()
+	write(dir, "export.pdf", []byte("%PDF-1.4\n"))
+	dest := filepath.Join(t.TempDir(), "backup", "streaks.pdf")
+	out, err = readOutputDir(dir, RunOptions{SavePath: dest})
+	var ref OutputFile
+	if err != nil || json.Unmarshal(out, &ref) != nil || ref.Path != dest || ref.MIME != "application/pdf" {
+		t.Fatalf("--save output: %s (%v)", out, err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "%PDF-1.4\n" {
+		t.Fatalf("unexpected saved file: %q", data)
+	}
+}
+
+func TestReadOutputDirKeepsExistingFiles(t *testing.T) {
+	pdf := func() string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "export.pdf"), []byte("%PDF-1.4\nnew"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	saved := func(out []byte) string {
+		t.Helper()
+		var ref OutputFile
+		if err := json.Unmarshal(out, &ref); err != nil {
+			t.Fatalf("unexpected output %s: %v", out, err)
+		}
+		return ref.Path
+	}
+
+	outDir := t.TempDir()
+	existing := filepath.Join(outDir, "export.pdf")
+	dest := filepath.Join(t.TempDir(), "streaks.pdf")
+	for _, path := range []string{existing, dest} {
+		if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		opts RunOptions
+		want string
+		kept string
+	}{
+		{RunOptions{OutputDir: outDir}, filepath.Join(outDir, "export-1.pdf"), existing},
+		{RunOptions{SavePath: dest}, strings.TrimSuffix(dest, ".pdf") + "-1.pdf", dest},
+	}
+	for _, tc := range cases {
+		out, err := readOutputDir(pdf(), tc.opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", tc.opts, err)
+		}
+		if got := saved(out); got != tc.want {
+			t.Fatalf("%+v: saved to %s, want %s", tc.opts, got, tc.want)
+		}
+		if data, _ := os.ReadFile(tc.kept); string(data) != "old" {
+			t.Fatalf("%+v: existing file was overwritten", tc.opts)
+		}
+	}
+
+	out, err := readOutputDir(pdf(), RunOptions{SavePath: dest, Overwrite: true})
+	if err != nil || saved(out) != dest {
+		t.Fatalf("--overwrite should replace the file: %s (%v)", out, err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "%PDF-1.4\nnew" {
+		t.Fatalf("unexpected file after overwrite: %q", data)
+	}
+}
+
+func TestOutputCacheRetention(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+	base, err := os.UserCacheDir()
+	if err != nil {
+		t.Skip("no user cache dir")
+	}
+	base = filepath.Join(base, "streaks-cli", "outputs")
+	old, recent := filepath.Join(base, "run-old"), filepath.Join(base, "run-recent")
+	for _, dir := range []string{old, recent} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-CacheRetention - time.Hour)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readOutputDir(dir, RunOptions{}); err != nil {
+		t.Fatalf("readOutputDir: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expired run directory should be removed: %v", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Fatalf("recent run directory should be kept: %v", err)
+	}
+}
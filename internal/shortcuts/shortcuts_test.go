@@ -1,6 +1,9 @@
 package shortcuts
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseList(t *testing.T) {
 	input := []byte("Example One (AAAAAAAA-BBBB-CCCC-DDDD-EEEEEEEEEEEE)\nPlain Shortcut\n")
@@ -15,3 +18,23 @@ func TestParseList(t *testing.T) {
 		t.Fatalf("unexpected shortcut[1]: %+v", shortcuts[1])
 	}
 }
+
+func TestListArgs(t *testing.T) {
+	if got := strings.Join(ListArgs(""), " "); got != "list --show-identifiers" {
+		t.Fatalf("unexpected args: %s", got)
+	}
+	if got := strings.Join(ListArgs(" Streaks "), " "); got != "list --show-identifiers --folder-name Streaks" {
+		t.Fatalf("unexpected folder args: %s", got)
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	args := RunArgs("Scan", []string{"/tmp/in.txt", "photo.jpg"}, "/tmp/out", RunOptions{OutputType: "public.json"})
+	want := "run Scan --input-path /tmp/in.txt --input-path photo.jpg --output-path /tmp/out --output-type public.json"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("unexpected args: %s", got)
+	}
+	if InputExt(InputText) != ".txt" || InputExt("") != ".json" {
+		t.Fatalf("unexpected input extensions")
+	}
+}
@@ -0,0 +1,211 @@
+// Package timers keeps a local record of task timers started and stopped
+// through the CLI, since Shortcuts cannot report which timers are running.
+package timers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"streaks-cli/internal/config"
+)
+
+const (
+	DefaultFileName   = "timers.json"
+	EnvTimersPath     = "STREAKS_CLI_TIMERS"
+	DefaultStaleAfter = 12 * time.Hour
+)
+
+type Running struct {
+	Task      string    `json:"task"`
+	StartedAt time.Time `json:"started_at"`
+	Shortcut  string    `json:"shortcut,omitempty"`
+}
+
+// Stale reports whether the timer has been running implausibly long, which
+// usually means it was stopped outside the CLI.
+func (r Running) Stale(now time.Time, staleAfter time.Duration) bool {
+	return staleAfter > 0 && now.Sub(r.StartedAt) > staleAfter
+}
+
+type Session struct {
+	Task  string    `json:"task"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Stale bool      `json:"stale,omitempty"`
+}
+
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+type State struct {
+	Running  []Running `json:"running"`
+	Sessions []Session `json:"sessions"`
+}
+
+func Path() (string, error) {
+	if override := os.Getenv(EnvTimersPath); override != "" {
+		return override, nil
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultFileName), nil
+}
+
+func Load() (State, error) {
+	path, err := Path()
+	if err != nil {
+		return State{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+func Save(state State) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Update loads the state, applies fn and saves the result.
+func Update(fn func(*State) error) error {
+	state, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&state); err != nil {
+		return err
+	}
+	return Save(state)
+}
+
+func (s *State) find(task string) int {
+	for i, running := range s.Running {
+		if strings.EqualFold(running.Task, task) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup returns the running timer for task, if any.
+func (s State) Lookup(task string) (Running, bool) {
+	if i := s.find(task); i >= 0 {
+		return s.Running[i], true
+	}
+	return Running{}, false
+}
+
+// Start records a running timer. When one is already running for the task it
+// is kept as is and returned with true.
+func (s *State) Start(task, shortcut string, at time.Time) (Running, bool) {
+	if i := s.find(task); i >= 0 {
+		return s.Running[i], true
+	}
+	running := Running{Task: task, StartedAt: at, Shortcut: shortcut}
+	s.Running = append(s.Running, running)
+	return running, false
+}
+
+// Stop ends the running timer for task and records the session. It returns
+// false when no start was recorded.
+func (s *State) Stop(task string, at time.Time, staleAfter time.Duration) (Session, bool) {
+	i := s.find(task)
+	if i < 0 {
+		return Session{}, false
+	}
+	running := s.Running[i]
+	s.Running = append(s.Running[:i], s.Running[i+1:]...)
+	session := Session{Task: running.Task, Start: running.StartedAt, End: at, Stale: running.Stale(at, staleAfter)}
+	s.Sessions = append(s.Sessions, session)
+	return session, true
+}
+
+type DayTotal struct {
+	Day      string
+	Task     string
+	Duration time.Duration
+	Sessions int
+}
+
+// Report totals focus time per task per local day between since and until.
+// Sessions crossing midnight are split across days; stale sessions are
+// skipped.
+func Report(sessions []Session, since, until time.Time, loc *time.Location) []DayTotal {
+	type key struct{ day, task string }
+	totals := make(map[key]*DayTotal)
+	for _, session := range sessions {
+		if session.Stale {
+			continue
+		}
+		start, end := session.Start.In(loc), session.End.In(loc)
+		if !since.IsZero() && start.Before(since) {
+			start = since.In(loc)
+		}
+		if !until.IsZero() && end.After(until) {
+			end = until.In(loc)
+		}
+		counted := map[string]bool{}
+		for start.Before(end) {
+			y, m, d := start.Date()
+			next := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+			chunkEnd := end
+			if next.Before(end) {
+				chunkEnd = next
+			}
+			k := key{day: start.Format("2006-01-02"), task: session.Task}
+			total, ok := totals[k]
+			if !ok {
+				total = &DayTotal{Day: k.day, Task: session.Task}
+				totals[k] = total
+			}
+			total.Duration += chunkEnd.Sub(start)
+			if !counted[k.day] {
+				total.Sessions++
+				counted[k.day] = true
+			}
+			start = chunkEnd
+		}
+	}
+	out := make([]DayTotal, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Day != out[j].Day {
+			return out[i].Day < out[j].Day
+		}
+		return out[i].Task < out[j].Task
+	})
+	return out
+}
@@ -0,0 +1,68 @@
+package timers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStartStopPersist(t *testing.T) {
+	t.Setenv(EnvTimersPath, filepath.Join(t.TempDir(), "timers.json"))
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	err := Update(func(s *State) error {
+		if _, already := s.Start("Read", "Start Read Timer", start); already {
+			t.Fatalf("first start should not be reported as running")
+		}
+		if existing, already := s.Start("read", "", start.Add(time.Minute)); !already || !existing.StartedAt.Equal(start) {
+			t.Fatalf("second start should return the running timer, got %+v", existing)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	state, err := Load()
+	if err != nil || len(state.Running) != 1 {
+		t.Fatalf("expected one running timer, got %+v (%v)", state, err)
+	}
+	session, ok := state.Stop("READ", start.Add(25*time.Minute), DefaultStaleAfter)
+	if !ok || session.Duration() != 25*time.Minute || session.Stale || len(state.Running) != 0 {
+		t.Fatalf("unexpected stop: %+v ok=%v state=%+v", session, ok, state)
+	}
+	if _, ok := state.Stop("Read", start, DefaultStaleAfter); ok {
+		t.Fatalf("stopping a timer that is not running should report false")
+	}
+
+	state.Start("Walk", "", start)
+	session, _ = state.Stop("Walk", start.Add(20*time.Hour), DefaultStaleAfter)
+	if !session.Stale {
+		t.Fatalf("a 20h timer should be stale")
+	}
+}
+
+func TestReport(t *testing.T) {
+	day := func(d, h, m int) time.Time { return time.Date(2026, 3, d, h, m, 0, 0, time.UTC) }
+	sessions := []Session{
+		{Task: "Read", Start: day(1, 9, 0), End: day(1, 9, 25)},
+		{Task: "Read", Start: day(1, 23, 50), End: day(2, 0, 20)},
+		{Task: "Walk", Start: day(2, 8, 0), End: day(2, 8, 30)},
+		{Task: "Walk", Start: day(2, 9, 0), End: day(3, 9, 0), Stale: true},
+		{Task: "Old", Start: day(1, 1, 0), End: day(1, 2, 0)},
+	}
+	totals := Report(sessions, day(1, 8, 0), time.Time{}, time.UTC)
+	want := []DayTotal{
+		{Day: "2026-03-01", Task: "Read", Duration: 35 * time.Minute, Sessions: 2},
+		{Day: "2026-03-02", Task: "Read", Duration: 20 * time.Minute, Sessions: 1},
+		{Day: "2026-03-02", Task: "Walk", Duration: 30 * time.Minute, Sessions: 1},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("got %+v", totals)
+	}
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Fatalf("total %d = %+v, want %+v", i, totals[i], want[i])
+		}
+	}
+}
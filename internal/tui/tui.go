@@ -0,0 +1,365 @@
+// Package tui implements the full-screen task interface behind `st ui`.
+// It only talks to Streaks through a Runner so it can be driven by fakes.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	ActionComplete   = "task-complete"
+	ActionMiss       = "task-miss"
+	ActionTimerStart = "timer-start"
+	ActionTimerStop  = "timer-stop"
+)
+
+type Row struct {
+	Task   string
+	Status string
+	Streak *int
+	Timer  string
+	Error  string
+}
+
+// Runner loads task rows and runs actions. Calls happen on background
+// goroutines, at most one refresh plus one action per task at a time.
+type Runner interface {
+	Rows(ctx context.Context) ([]Row, error)
+	Run(ctx context.Context, action, task string) error
+}
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+	reverse     = "\x1b[7m"
+	reset       = "\x1b[0m"
+)
+
+type eventKind int
+
+const (
+	eventKey eventKind = iota
+	eventEOF
+	eventRows
+	eventAction
+)
+
+type event struct {
+	kind   eventKind
+	key    string
+	rows   []Row
+	action string
+	task   string
+	err    error
+}
+
+// Run draws the interface on out and reads keys from in until the user quits
+// or in is exhausted and all running actions have finished. in is expected to
+// be a terminal in raw mode.
+func Run(ctx context.Context, runner Runner, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan event, 16)
+	send := func(ev event) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+		}
+	}
+	go readKeys(in, send)
+
+	m := newModel()
+	pending := 0
+	refresh := func() {
+		m.refreshing = true
+		pending++
+		go func() {
+			rows, err := runner.Rows(ctx)
+			send(event{kind: eventRows, rows: rows, err: err})
+		}()
+	}
+	runAction := func(action, task string) {
+		m.busy[task] = action
+		delete(m.errors, task)
+		pending++
+		go func() {
+			err := runner.Run(ctx, action, task)
+			send(event{kind: eventAction, action: action, task: task, err: err})
+		}()
+	}
+
+	if _, err := io.WriteString(out, enterScreen); err != nil {
+		return err
+	}
+	defer io.WriteString(out, leaveScreen)
+
+	refresh()
+	eof := false
+	for {
+		if _, err := io.WriteString(out, m.render()); err != nil {
+			return err
+		}
+		if eof && pending == 0 {
+			return nil
+		}
+		var ev event
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev = <-events:
+		}
+		switch ev.kind {
+		case eventEOF:
+			eof = true
+		case eventRows:
+			pending--
+			m.setRows(ev.rows, ev.err)
+		case eventAction:
+			pending--
+			m.finishAction(ev.action, ev.task, ev.err)
+			if ev.err == nil {
+				refresh()
+			}
+		case eventKey:
+			cmd := m.handleKey(ev.key)
+			switch {
+			case cmd.quit:
+				return nil
+			case cmd.refresh:
+				refresh()
+			case cmd.action != "":
+				runAction(cmd.action, cmd.task)
+			}
+		}
+	}
+}
+
+func readKeys(in io.Reader, send func(event)) {
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			send(event{kind: eventKey, key: key})
+		}
+		if err != nil {
+			send(event{kind: eventEOF})
+			return
+		}
+	}
+}
+
+// parseKeys splits raw terminal input into key names. Arrow keys arrive as
+// escape sequences; everything else maps to the typed character.
+func parseKeys(buf []byte) []string {
+	keys := make([]string, 0, len(buf))
+	for i := 0; i < len(buf); i++ {
+		b := buf[i]
+		switch {
+		case b == 0x1b && i+2 < len(buf) && (buf[i+1] == '[' || buf[i+1] == 'O'):
+			switch buf[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case b == 0x1b:
+			keys = append(keys, "esc")
+		case b == 0x03:
+			keys = append(keys, "ctrl+c")
+		case b == '\r' || b == '\n':
+			keys = append(keys, "enter")
+		default:
+			keys = append(keys, string(rune(b)))
+		}
+	}
+	return keys
+}
+
+type command struct {
+	quit    bool
+	refresh bool
+	action  string
+	task    string
+}
+
+type model struct {
+	rows       []Row
+	cursor     int
+	busy       map[string]string
+	errors     map[string]string
+	message    string
+	loadErr    string
+	refreshing bool
+}
+
+func newModel() *model {
+	return &model{busy: map[string]string{}, errors: map[string]string{}}
+}
+
+func (m *model) handleKey(key string) command {
+	switch key {
+	case "q", "esc", "ctrl+c":
+		return command{quit: true}
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return command{}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+		return command{}
+	case "r":
+		if m.refreshing {
+			return command{}
+		}
+		m.message = ""
+		return command{refresh: true}
+	}
+
+	var action string
+	switch key {
+	case "c", "enter":
+		action = ActionComplete
+	case "m":
+		action = ActionMiss
+	case "s":
+		action = ActionTimerStart
+	case "x":
+		action = ActionTimerStop
+	case "t":
+		action = ActionTimerStart
+		if row, ok := m.current(); ok && row.Timer == "running" {
+			action = ActionTimerStop
+		}
+	default:
+		return command{}
+	}
+	row, ok := m.current()
+	if !ok {
+		return command{}
+	}
+	if running, busy := m.busy[row.Task]; busy {
+		m.message = fmt.Sprintf("%s is still running %s", row.Task, running)
+		return command{}
+	}
+	m.message = ""
+	return command{action: action, task: row.Task}
+}
+
+func (m *model) current() (Row, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return Row{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+func (m *model) setRows(rows []Row, err error) {
+	m.refreshing = false
+	if err != nil {
+		m.loadErr = err.Error()
+		return
+	}
+	m.loadErr = ""
+	selected := ""
+	if row, ok := m.current(); ok {
+		selected = row.Task
+	}
+	m.rows = rows
+	m.cursor = 0
+	for i, row := range rows {
+		if row.Task == selected {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+func (m *model) finishAction(action, task string, err error) {
+	delete(m.busy, task)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		m.errors[task] = fmt.Sprintf("%s failed: %v", action, err)
+		return
+	}
+	m.message = fmt.Sprintf("%s: %s done", task, action)
+}
+
+var busyLabels = map[string]string{
+	ActionComplete:   "completing...",
+	ActionMiss:       "marking missed...",
+	ActionTimerStart: "starting timer...",
+	ActionTimerStop:  "stopping timer...",
+}
+
+func (m *model) render() string {
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	title := fmt.Sprintf("Streaks - %d tasks", len(m.rows))
+	if m.refreshing {
+		title += " (refreshing...)"
+	}
+	lines := []string{title, ""}
+
+	width := len("TASK")
+	for _, row := range m.rows {
+		if len(row.Task) > width {
+			width = len(row.Task)
+		}
+	}
+	lines = append(lines, fmt.Sprintf("  %-*s  %-8s  %-6s  %-7s", width, "TASK", "STATUS", "STREAK", "TIMER"))
+	if len(m.rows) == 0 && !m.refreshing && m.loadErr == "" {
+		lines = append(lines, "  No tasks")
+	}
+	for i, row := range m.rows {
+		status := row.Status
+		if status == "" {
+			status = "unknown"
+		}
+		streak := "-"
+		if row.Streak != nil {
+			streak = strconv.Itoa(*row.Streak)
+		}
+		timer := row.Timer
+		if timer == "" {
+			timer = "-"
+		}
+		line := fmt.Sprintf("%-*s  %-8s  %-6s  %-7s", width, row.Task, status, streak, timer)
+		if action, ok := m.busy[row.Task]; ok {
+			line += "  " + busyLabels[action]
+		}
+		if i == m.cursor {
+			line = "> " + reverse + line + reset
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+		if msg := m.errors[row.Task]; msg != "" {
+			lines = append(lines, "    ! "+msg)
+		} else if row.Error != "" {
+			lines = append(lines, "    ! "+row.Error)
+		}
+	}
+
+	lines = append(lines, "")
+	if m.loadErr != "" {
+		lines = append(lines, "! could not load tasks: "+m.loadErr)
+	}
+	if m.message != "" {
+		lines = append(lines, m.message)
+	}
+	lines = append(lines, "c complete  m miss  t timer  s/x start/stop timer  r refresh  j/k move  q quit")
+	b.WriteString(strings.Join(lines, "\r\n"))
+	return b.String()
+}
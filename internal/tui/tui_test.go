@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeRunner struct {
+	mu    sync.Mutex
+	rows  []Row
+	calls []string
+	fail  map[string]error
+}
+
+func (f *fakeRunner) Rows(context.Context) ([]Row, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Row(nil), f.rows...), nil
+}
+
+func (f *fakeRunner) Run(_ context.Context, action, task string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, action+" "+task)
+	if err := f.fail[task]; err != nil {
+		return err
+	}
+	for i := range f.rows {
+		if f.rows[i].Task != task {
+			continue
+		}
+		switch action {
+		case ActionComplete:
+			f.rows[i].Status = "done"
+		case ActionTimerStart:
+			f.rows[i].Timer = "running"
+		}
+	}
+	return nil
+}
+
+// screen records rendered frames and signals whenever one is written.
+type screen struct {
+	mu     sync.Mutex
+	frames []string
+	wrote  chan struct{}
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	s.frames = append(s.frames, string(p))
+	s.mu.Unlock()
+	select {
+	case s.wrote <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+func (s *screen) waitFor(t *testing.T, text string) string {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		s.mu.Lock()
+		last := ""
+		if len(s.frames) > 0 {
+			last = s.frames[len(s.frames)-1]
+		}
+		s.mu.Unlock()
+		if strings.Contains(last, text) {
+			return last
+		}
+		select {
+		case <-s.wrote:
+		case <-deadline:
+			t.Fatalf("timed out waiting for %q; last frame:\n%s", text, last)
+		}
+	}
+}
+
+func TestRunDrivesActions(t *testing.T) {
+	runner := &fakeRunner{
+		rows: []Row{{Task: "Read", Status: "pending"}, {Task: "Walk", Status: "pending"}},
+		fail: map[string]error{"Walk": errors.New("shortcut timed out")},
+	}
+	in, keys := io.Pipe()
+	out := &screen{wrote: make(chan struct{}, 1)}
+	done := make(chan error, 1)
+	go func() { done <- Run(context.Background(), runner, in, out) }()
+
+	out.waitFor(t, "Walk")
+	keys.Write([]byte("c"))
+	out.waitFor(t, "Read: task-complete done")
+	keys.Write([]byte("t"))
+	out.waitFor(t, "running")
+	keys.Write([]byte("\x1b[Bc"))
+	frame := out.waitFor(t, "! task-complete failed: shortcut timed out")
+	if !strings.Contains(frame, "> "+reverse+"Walk") {
+		t.Fatalf("expected Walk to be highlighted:\n%s", frame)
+	}
+	keys.Write([]byte("q"))
+	defer keys.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run did not exit after q")
+	}
+	want := []string{"task-complete Read", "timer-start Read", "task-complete Walk"}
+	if strings.Join(runner.calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("calls = %v, want %v", runner.calls, want)
+	}
+}
+
+func TestRunExitsAfterInputEndsAndActionsFinish(t *testing.T) {
+	runner := &fakeRunner{}
+	out := &screen{wrote: make(chan struct{}, 1)}
+	if err := Run(context.Background(), runner, strings.NewReader(""), out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	last := out.frames[len(out.frames)-1]
+	if last != leaveScreen {
+		t.Fatalf("expected terminal to be restored, got %q", last)
+	}
+}
+
+func TestModelKeys(t *testing.T) {
+	m := newModel()
+	m.setRows([]Row{{Task: "Read", Timer: "running"}, {Task: "Walk"}}, nil)
+
+	if cmd := m.handleKey("t"); cmd.action != ActionTimerStop || cmd.task != "Read" {
+		t.Fatalf("t on a running timer should stop it, got %+v", cmd)
+	}
+	m.busy["Read"] = ActionTimerStop
+	if cmd := m.handleKey("c"); cmd.action != "" || !strings.Contains(m.message, "still running") {
+		t.Fatalf("busy task should not start another action, got %+v (%s)", cmd, m.message)
+	}
+	m.handleKey("down")
+	m.handleKey("down")
+	if cmd := m.handleKey("m"); cmd.action != ActionMiss || cmd.task != "Walk" {
+		t.Fatalf("expected miss on Walk, got %+v", cmd)
+	}
+
+	m.setRows([]Row{{Task: "Walk"}, {Task: "Read"}}, nil)
+	if row, _ := m.current(); row.Task != "Walk" {
+		t.Fatalf("refresh should keep the selection, got %s", row.Task)
+	}
+	m.setRows(nil, errors.New("offline"))
+	if len(m.rows) != 2 || !strings.Contains(m.render(), "could not load tasks: offline") {
+		t.Fatalf("load errors should be shown inline without dropping rows")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1b[Bq\r\x03\x1b"))
+	want := []string{"j", "up", "down", "q", "enter", "ctrl+c", "esc"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("parseKeys = %v, want %v", got, want)
+	}
+}